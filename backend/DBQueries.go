@@ -4,35 +4,128 @@ package main
 
 import (
 	"context"
+	"errors"
+	"strconv"
+
+	"backend/morphology"
+	"backend/questionbank"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // Structure of a basic question document in MongoDB
 type Question struct {
-	ID         int      `bson:"id" json:"id"`
+	ID         string   `bson:"id" json:"id"`
 	Text       string   `bson:"text" json:"text"`
 	Choices    []string `bson:"choices" json:"choices"`
 	Answer     string   `bson:"answer" json:"answer"`
 	Difficulty string   `bson:"difficulty" json:"difficulty"`
+	Type       string   `bson:"question_type,omitempty" json:"type,omitempty"` // MC|TF|FR, empty on legacy questions
+
+	Tree *morphology.TreeNode `bson:"tree,omitempty" json:"tree,omitempty"` // Structure of the word asked about (sent with the grade, it's the answer)
 }
 
 // Structure of a leaderboard entry
 type LeaderboardEntry struct {
-	Username string `bson:"username" json:"username"`
-	Score    int    `bson:"score" json:"score"`
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Username  string        `bson:"username" json:"username"`
+	Score     int           `bson:"score" json:"score"`
+	SeasonID  string        `bson:"season_id,omitempty" json:"seasonId,omitempty"`
+	GroupCode string        `bson:"group_code,omitempty" json:"-"` // Private join code, never sent with board entries
+	GroupOnly bool          `bson:"group_only,omitempty" json:"-"` // Kept off the global board
+	Hidden    bool          `bson:"hidden,omitempty" json:"-"`     // Hidden by a moderator
+	Identity  string        `bson:"identity,omitempty" json:"-"`   // Hashed client IP, used for identity bans
+}
+
+// Retrieves a random question of one of the given types from the active question bank version, returning a
+// Question struct and error (if any). Free-response answers are graded server-side, so theirs is left out, and
+// the word's tree gives structure questions away, so it only comes back with the grade.
+func GetRandomQuestion(client *mongo.Client, types []string) (*Question, error) {
+	// Only questions in the active bank version are served
+	question, err := sampleQuestion(client, bson.D{
+		{Key: "active", Value: true},
+		{Key: "question_type", Value: bson.D{{Key: "$in", Value: types}}},
+	})
+	if err == nil {
+		question.Tree = nil
+		if question.Type == "FR" {
+			question.Answer = ""
+		}
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return question, err
+	}
+
+	// Nothing published yet: fall back to legacy (unversioned, numeric id) questions. Once a
+	// version exists, an empty match means the active bank has none of these types.
+	published, err := questionbank.HasVersions(client)
+	if err != nil {
+		return nil, err
+	}
+	if published {
+		return nil, mongo.ErrNoDocuments
+	}
+	return sampleQuestion(client, bson.D{
+		{Key: "versions", Value: bson.D{{Key: "$exists", Value: false}}},
+		{Key: "active", Value: bson.D{{Key: "$ne", Value: false}}},
+	})
+}
+
+// Outcome of grading a submitted answer
+type AnswerResult struct {
+	Correct      bool                          `json:"correct"`
+	Answer       string                        `json:"answer"`                 // The expected answer
+	Explanation  string                        `json:"explanation,omitempty"`  // Morpheme breakdown of the word asked about
+	Segmentation *morphology.SegmentationGrade `json:"segmentation,omitempty"` // Boundary feedback on free-response answers
+	Tree         *morphology.TreeNode          `json:"tree,omitempty"`         // Structure of the word asked about, for drawing
+}
+
+// Grades an answer to the question with the given id. Free-response answers are segmentations and are compared by
+// morpheme boundary position; every other answer must match the correct choice exactly.
+func GradeAnswer(client *mongo.Client, id string, answer string) (*AnswerResult, error) {
+	collection := client.Database("capymorphDB").Collection("questions")
+
+	// Legacy questions have numeric ids
+	ids := bson.A{id}
+	if n, err := strconv.Atoi(id); err == nil {
+		ids = append(ids, n)
+	}
+
+	// Decode only what grading needs (a legacy numeric id wouldn't fit Question.ID)
+	var question struct {
+		Answer      string               `bson:"answer"`
+		Type        string               `bson:"question_type"`
+		Explanation string               `bson:"explanation"` // Missing on legacy questions
+		Tree        *morphology.TreeNode `bson:"tree"`
+	}
+	err := collection.FindOne(context.TODO(), bson.D{{Key: "id", Value: bson.D{{Key: "$in", Value: ids}}}}).Decode(&question)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AnswerResult{Answer: question.Answer, Explanation: question.Explanation, Tree: question.Tree}
+	if question.Type == "FR" {
+		grade := morphology.GradeSegmentation(question.Answer, answer)
+		result.Correct = grade.Correct
+		result.Segmentation = &grade
+	} else {
+		result.Correct = answer == question.Answer
+	}
+	return result, nil
 }
 
-// Retrieves a random question from the MongoDB collection, returning a Question struct and error (if any)
-func GetRandomQuestion(client *mongo.Client) (*Question, error) {
+// Picks one random question matching filter
+func sampleQuestion(client *mongo.Client, filter bson.D) (*Question, error) {
 	// Access the database and questions collection
 	collection := client.Database("capymorphDB").Collection("questions")
 
 	// Define the aggregation pipeline to get a random document
 	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
 		{{Key: "$sample", Value: bson.D{{Key: "size", Value: 1}}}},
+		// Legacy documents have numeric ids
+		{{Key: "$set", Value: bson.D{{Key: "id", Value: bson.D{{Key: "$toString", Value: "$id"}}}}}},
 	}
 
 	// Execute the aggregation
@@ -56,46 +149,19 @@ func GetRandomQuestion(client *mongo.Client) (*Question, error) {
 	return &questions[0], nil // Return the first (and only) question
 }
 
-// Retrieves the top N leaderboard entries from the MongoDB collection, returning a slice of LeaderboardEntry structs and error (if any)
-func GetLeaderboards(client *mongo.Client, numPlayers int) ([]LeaderboardEntry, error) {
-	// Access the database and leaderboards collection
+// AddScoreToLeaderboards writes an entry through to MongoDB and, unless it is group-only, the global
+// in-memory index. Returns the stored entry and the user's global rank (0 for group-only entries).
+func AddScoreToLeaderboards(client *mongo.Client, idx *LeaderboardIndex, entry LeaderboardEntry) (*LeaderboardEntry, int64, error) {
 	collection := client.Database("capymorphDB").Collection("leaderboards")
 
-	// Define the find options to sort by score descending and limit results
-	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{Key: "score", Value: -1}})
-	findOptions.SetLimit(int64(numPlayers))
-
-	// Execute the find query
-	cursor, err := collection.Find(context.TODO(), bson.D{}, findOptions)
-	if err != nil {
-		return nil, err
+	entry.ID = bson.NewObjectID()
+	if _, err := collection.InsertOne(context.TODO(), entry); err != nil {
+		return nil, 0, err
 	}
-	defer cursor.Close(context.TODO())
 
-	// Retrieve the leaderboard entries
-	var leaderboards []LeaderboardEntry
-	if err := cursor.All(context.TODO(), &leaderboards); err != nil {
-		return nil, err
+	if entry.GroupOnly {
+		return &entry, 0, nil
 	}
-
-	// Return the leaderboard entries
-	return leaderboards, nil
-}
-
-// AddScoreToLeaderboards inserts a (username, score) entry and returns the user's rank.
-func AddScoreToLeaderboards(client *mongo.Client, username string, score int) (int64, error) {
-	collection := client.Database("capymorphDB").Collection("leaderboards")
-
-	_, err := collection.InsertOne(context.TODO(), LeaderboardEntry{Username: username, Score: score})
-	if err != nil {
-		return 0, err
-	}
-
-	countAbove, err := collection.CountDocuments(context.TODO(), bson.M{"score": bson.M{"$gt": score}})
-	if err != nil {
-		return 0, err
-	}
-
-	return countAbove + 1, nil
+	idx.Insert(entry)
+	return &entry, idx.RankOf(entry.Score), nil
 }
@@ -3,14 +3,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"backend/database"
+
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
@@ -19,18 +25,44 @@ var PORT string = ":8080" // Constant server port for endpoints
 func main() {
 
 	var client atomic.Value  // Avoid race conditions on client access
+	var board atomic.Value   // *LeaderboardIndex, stored once loaded from MongoDB
+	var bans atomic.Value    // *BanList, stored once loaded from MongoDB
+	var season atomic.Value  // *Season currently running (nil when no season is running)
+	groupBoards := NewGroupBoards()
 
 	// Background Mongo connector with retry
 	go func() {
 		for {
-			c, err := ConnectDB()
+			c, err := database.ConnectDB()
 			if err != nil {
 				log.Println("MongoDB not ready, retrying:", err)
 				time.Sleep(5 * time.Second)  // Retry after 5 second delay
 				continue
 			}
+			if err := EnsureGroupIndexes(c); err != nil {
+				log.Println("Failed to create group indexes:", err)
+			}
+			if err := EnsureBanIndexes(c); err != nil {
+				log.Println("Failed to create ban indexes:", err)
+			}
+			// Build the in-memory leaderboard (for the active season) before accepting traffic
+			if err := RefreshSeason(c, &season, &board); err != nil {
+				log.Println("Failed to load leaderboards, retrying:", err)
+				_ = c.Disconnect(context.TODO())
+				time.Sleep(5 * time.Second)
+				continue
+			}
+			banList, err := LoadBanList(c)
+			if err != nil {
+				log.Println("Failed to load bans, retrying:", err)
+				_ = c.Disconnect(context.TODO())
+				time.Sleep(5 * time.Second)
+				continue
+			}
+			bans.Store(banList)
 			client.Store(c)
-			log.Println("Successfully connected to MongoDB")
+			log.Printf("Successfully connected to MongoDB (%d leaderboard entries indexed)", board.Load().(*LeaderboardIndex).Len())
+			go WatchSeasons(c, &season, &board, time.Minute)
 			return
 		}
 	}()
@@ -39,6 +71,16 @@ func main() {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
 	r.SetTrustedProxies([]string{"127.0.0.1", "0.0.0.0"})
+	// Behind Fly's proxy the real client address arrives in Fly-Client-IP (only trust it on Fly)
+	if os.Getenv("FLY_APP_NAME") != "" {
+		r.TrustedPlatform = "Fly-Client-IP"
+	}
+
+	// Per-route rate limiters, keyed by client IP
+	questionLimiter := NewRateLimiter(2, 10)       // 2 req/s sustained, bursts of 10
+	leaderboardLimiter := NewRateLimiter(2, 10)    // 2 req/s sustained, bursts of 10
+	addScoreLimiter := NewRateLimiter(1.0/10.0, 3) // 1 submission every 10s, bursts of 3
+	groupLimiter := NewRateLimiter(1.0/60.0, 3)    // 1 new group a minute, bursts of 3
 
 	// Declare api endpoints group
 	api := r.Group("/api")
@@ -53,7 +95,7 @@ func main() {
 	})
 
 	// Question retreival endpoint
-	api.GET("/question", func(c *gin.Context) {
+	api.GET("/question", questionLimiter.Middleware(), func(c *gin.Context) {
 		// Retrieve question from DB
 		val := client.Load()
 		if val == nil {
@@ -62,8 +104,21 @@ func main() {
 		}
 		mongoClient := val.(*mongo.Client)
 
-		question, err := GetRandomQuestion(mongoClient)
+		// Free-response questions are only served to clients that ask for them (?types=MC,TF,FR)
+		types := strings.Split(c.DefaultQuery("types", "MC,TF"), ",")
+		for _, t := range types {
+			if t != "MC" && t != "TF" && t != "FR" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "types must be a comma-separated list of MC, TF and FR"})
+				return
+			}
+		}
+
+		question, err := GetRandomQuestion(mongoClient, types)
 		// Return error if retrieval fails
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(404, gin.H{"error": "No questions of the requested types"})
+			return
+		}
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Failed to retrieve question"})
 			return
@@ -72,8 +127,39 @@ func main() {
 		c.JSON(200, question)
 	})
 
+	// Answer grading endpoint (the only way to check free-response answers)
+	api.POST("/question/:id/answer", questionLimiter.Middleware(), func(c *gin.Context) {
+		type answerRequest struct {
+			Answer string `json:"answer"`
+		}
+
+		var req answerRequest
+		if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Answer) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "answer is required"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		result, err := GradeAnswer(mongoClient, c.Param("id"), req.Answer)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "question not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to grade answer"})
+			return
+		}
+		c.JSON(200, result)
+	})
+
 	// Retreive leaderboards endpoint
-	api.GET("/leaderboards/:numPlayers", func(c *gin.Context) {
+	api.GET("/leaderboards/:numPlayers", leaderboardLimiter.Middleware(), func(c *gin.Context) {
 		// Pull numPlayers from URL param
 		numPlayersStr := c.Param("numPlayers")
 		numPlayers, err := strconv.Atoi(numPlayersStr)
@@ -81,29 +167,55 @@ func main() {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "numPlayers must be a positive integer"})
 			return
 		}
-		// Retrieve leaderboards from DB
-		val := client.Load()
+		// Serve leaderboards from the in-memory index
+		val := board.Load()
 		if val == nil {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
 			return
 		}
-		mongoClient := val.(*mongo.Client)
+		idx := val.(*LeaderboardIndex)
 
-		leaderboards, err := GetLeaderboards(mongoClient, numPlayers)
-		// Return error if retrieval fails
+		// Return leaderboards as JSON
+		c.JSON(200, idx.Top(numPlayers))
+	})
+
+	// Retrieve the leaderboard window around a submitted entry
+	api.GET("/leaderboards/around/:id", leaderboardLimiter.Middleware(), func(c *gin.Context) {
+		id, err := bson.ObjectIDFromHex(c.Param("id"))
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Failed to retrieve leaderboards"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a valid entry id"})
 			return
 		}
-		// Return leaderboards as JSON
-		c.JSON(200, leaderboards)
+		radius := 5
+		if r := c.Query("radius"); r != "" {
+			radius, err = strconv.Atoi(r)
+			if err != nil || radius < 0 || radius > 50 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be an integer between 0 and 50"})
+				return
+			}
+		}
+
+		val := board.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		idx := val.(*LeaderboardIndex)
+
+		window, ok := idx.Around(id, radius)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "leaderboard entry not found"})
+			return
+		}
+		c.JSON(http.StatusOK, window)
 	})
 
 	// Add a score and name to the leaderboards endpoint
-	api.POST("/addScoreLeaderboards", func(c *gin.Context) {
+	api.POST("/addScoreLeaderboards", addScoreLimiter.Middleware(), func(c *gin.Context) {
 		type addScoreRequest struct {
-			Username string `json:"username"`
-			Score    int    `json:"score"`
+			Username  string `json:"username"`
+			Score     int    `json:"score"`
+			GroupCode string `json:"groupCode"`
 		}
 
 		var req addScoreRequest
@@ -117,6 +229,7 @@ func main() {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "username is required"})
 			return
 		}
+		req.GroupCode = strings.ToUpper(strings.TrimSpace(req.GroupCode))
 
 		val := client.Load()
 		if val == nil {
@@ -124,14 +237,556 @@ func main() {
 			return
 		}
 		mongoClient := val.(*mongo.Client)
+		idx := board.Load().(*LeaderboardIndex)
+
+		identity := identityOf(c.ClientIP())
+		if bans.Load().(*BanList).Banned(req.Username, identity) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "submissions from this player are blocked"})
+			return
+		}
 
-		rank, err := AddScoreToLeaderboards(mongoClient, req.Username, req.Score)
+		entry := LeaderboardEntry{Username: req.Username, Score: req.Score, Identity: identity, SeasonID: idx.SeasonID()}
+
+		// Tag the entry with its group (if any) and let the group decide whether it also goes global
+		var groupIdx *LeaderboardIndex
+		if req.GroupCode != "" {
+			group, err := GetGroup(mongoClient, req.GroupCode)
+			if err != nil {
+				respondGroupError(c, err, groupBoards, req.GroupCode)
+				return
+			}
+			if groupIdx, err = groupBoards.Get(mongoClient, group.Code); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load group leaderboard"})
+				return
+			}
+			entry.GroupCode = group.Code
+			entry.GroupOnly = !group.GlobalBoard
+		}
+
+		stored, rank, err := AddScoreToLeaderboards(mongoClient, idx, entry)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to insert score"})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"rank": rank})
+		resp := gin.H{"id": stored.ID}
+		if !stored.GroupOnly {
+			resp["rank"] = rank
+		}
+		if groupIdx != nil {
+			groupIdx.Insert(*stored)
+			resp["groupRank"] = groupIdx.RankOf(stored.Score)
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	// Create a class/group leaderboard; the owner token is only ever returned here
+	api.POST("/groups", groupLimiter.Middleware(), func(c *gin.Context) {
+		type createGroupRequest struct {
+			Name          string `json:"name"`
+			GlobalBoard   *bool  `json:"globalBoard"`   // Defaults to true
+			ExpiresInDays int    `json:"expiresInDays"` // Defaults to ~a semester
+		}
+
+		var req createGroupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expected JSON body with name (string)"})
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > 64 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name is required (max 64 characters)"})
+			return
+		}
+		ttl := groupDefaultTTL
+		if req.ExpiresInDays != 0 {
+			ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+			if req.ExpiresInDays < 0 || ttl > groupMaxTTL {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "expiresInDays must be between 1 and 365"})
+				return
+			}
+		}
+		globalBoard := req.GlobalBoard == nil || *req.GlobalBoard
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		group, ownerToken, err := CreateGroup(mongoClient, req.Name, globalBoard, ttl)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"group": group, "ownerToken": ownerToken})
+	})
+
+	// Group details (used to validate a join code)
+	api.GET("/groups/:code", leaderboardLimiter.Middleware(), func(c *gin.Context) {
+		code := strings.ToUpper(c.Param("code"))
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		group, err := GetGroup(mongoClient, code)
+		if err != nil {
+			respondGroupError(c, err, groupBoards, code)
+			return
+		}
+		c.JSON(http.StatusOK, group)
+	})
+
+	// Retrieve a group's leaderboard
+	api.GET("/groups/:code/leaderboards/:numPlayers", leaderboardLimiter.Middleware(), func(c *gin.Context) {
+		code := strings.ToUpper(c.Param("code"))
+		numPlayers, err := strconv.Atoi(c.Param("numPlayers"))
+		if err != nil || numPlayers <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "numPlayers must be a positive integer"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		if _, err := GetGroup(mongoClient, code); err != nil {
+			respondGroupError(c, err, groupBoards, code)
+			return
+		}
+		groupIdx, err := groupBoards.Get(mongoClient, code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve leaderboards"})
+			return
+		}
+		c.JSON(http.StatusOK, groupIdx.Top(numPlayers))
+	})
+
+	// Group-scoped moderation: the group owner can take an entry off their board
+	api.DELETE("/groups/:code/entries/:id", func(c *gin.Context) {
+		code := strings.ToUpper(c.Param("code"))
+		id, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a valid entry id"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		group, err := GetGroup(mongoClient, code)
+		if err != nil && !errors.Is(err, ErrGroupExpired) {
+			respondGroupError(c, err, groupBoards, code)
+			return
+		}
+		if !group.Authorized(bearerToken(c)) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid group owner token"})
+			return
+		}
+
+		removed, err := RemoveGroupEntry(mongoClient, code, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove entry"})
+			return
+		}
+		if !removed {
+			c.JSON(http.StatusNotFound, gin.H{"error": "leaderboard entry not found in this group"})
+			return
+		}
+		if err := RecordAudit(mongoClient, "group:"+code, "group_remove", id.Hex(), ""); err != nil {
+			log.Println("Failed to record audit entry:", err)
+		}
+		// Group-only entries are gone entirely; shared ones stay on the global board
+		groupBoards.Drop(code)
+		c.JSON(http.StatusOK, gin.H{"removed": id})
+	})
+
+	// Close a group early (owner only)
+	api.DELETE("/groups/:code", func(c *gin.Context) {
+		code := strings.ToUpper(c.Param("code"))
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		group, err := GetGroup(mongoClient, code)
+		if err != nil {
+			respondGroupError(c, err, groupBoards, code)
+			return
+		}
+		if !group.Authorized(bearerToken(c)) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid group owner token"})
+			return
+		}
+
+		if err := ExpireGroup(mongoClient, code); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close group"})
+			return
+		}
+		if err := RecordAudit(mongoClient, "group:"+code, "group_close", code, ""); err != nil {
+			log.Println("Failed to record audit entry:", err)
+		}
+		groupBoards.Drop(code)
+		c.JSON(http.StatusOK, gin.H{"closed": code})
+	})
+
+	// List every season, newest first
+	api.GET("/seasons", leaderboardLimiter.Middleware(), func(c *gin.Context) {
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		seasons, err := GetSeasons(mongoClient)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve seasons"})
+			return
+		}
+		c.JSON(http.StatusOK, seasons)
+	})
+
+	// The season the default board is showing
+	api.GET("/seasons/current", leaderboardLimiter.Middleware(), func(c *gin.Context) {
+		active, _ := season.Load().(*Season)
+		if active == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no season is currently running"})
+			return
+		}
+		c.JSON(http.StatusOK, active)
+	})
+
+	// Standings for a season: live for the active season, archived once it has ended
+	api.GET("/seasons/:id/leaderboards", leaderboardLimiter.Middleware(), func(c *gin.Context) {
+		limit := 10
+		if l := c.Query("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+				return
+			}
+			limit = n
+		}
+
+		if active, _ := season.Load().(*Season); active != nil && active.ID == c.Param("id") {
+			c.JSON(http.StatusOK, board.Load().(*LeaderboardIndex).Top(limit))
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		s, err := GetSeason(mongoClient, c.Param("id"))
+		if errors.Is(err, ErrSeasonNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "season not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve season"})
+			return
+		}
+		if s.ArchivedAt == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "season has not been archived yet"})
+			return
+		}
+
+		standings, err := GetSeasonStandings(mongoClient, s.ID, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve leaderboards"})
+			return
+		}
+		c.JSON(http.StatusOK, standings)
+	})
+
+	// Admin moderation endpoints (bearer token from ADMIN_TOKENS)
+	admin := api.Group("/admin", RequireAdmin())
+
+	// Hide or restore an entry; hidden entries stay in MongoDB but leave every board
+	setHidden := func(hidden bool) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			type moderateRequest struct {
+				Reason string `json:"reason"`
+			}
+			var req moderateRequest
+			_ = c.ShouldBindJSON(&req) // Reason is optional
+
+			id, err := bson.ObjectIDFromHex(c.Param("id"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a valid entry id"})
+				return
+			}
+
+			val := client.Load()
+			if val == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+				return
+			}
+			mongoClient := val.(*mongo.Client)
+			idx := board.Load().(*LeaderboardIndex)
+
+			entry, err := SetEntryHidden(mongoClient, id, hidden)
+			if errors.Is(err, ErrEntryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "leaderboard entry not found"})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update entry"})
+				return
+			}
+
+			action := "unhide"
+			if hidden {
+				action = "hide"
+				idx.Remove(id)
+			} else if !entry.GroupOnly && (idx.SeasonID() == "" || entry.SeasonID == idx.SeasonID()) {
+				idx.Insert(*entry)
+			}
+			if entry.GroupCode != "" {
+				groupBoards.Drop(entry.GroupCode)
+			}
+
+			if err := RecordAudit(mongoClient, c.GetString("admin"), action, id.Hex(), req.Reason); err != nil {
+				log.Println("Failed to record audit entry:", err)
+			}
+			c.JSON(http.StatusOK, entry)
+		}
+	}
+	admin.POST("/entries/:id/hide", setHidden(true))
+	admin.POST("/entries/:id/unhide", setHidden(false))
+
+	// Permanently delete an entry
+	admin.DELETE("/entries/:id", func(c *gin.Context) {
+		id, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a valid entry id"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+		idx := board.Load().(*LeaderboardIndex)
+
+		entry, err := DeleteLeaderboardEntry(mongoClient, id)
+		if errors.Is(err, ErrEntryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "leaderboard entry not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete entry"})
+			return
+		}
+
+		idx.Remove(id)
+		if entry.GroupCode != "" {
+			groupBoards.Drop(entry.GroupCode)
+		}
+
+		if err := RecordAudit(mongoClient, c.GetString("admin"), "delete", id.Hex(), c.Query("reason")); err != nil {
+			log.Println("Failed to record audit entry:", err)
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": entry})
+	})
+
+	// List active bans
+	admin.GET("/bans", func(c *gin.Context) {
+		val := bans.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		c.JSON(http.StatusOK, val.(*BanList).All())
+	})
+
+	// Ban a username or identity from future submissions. Identity bans can name an
+	// entry id instead of a value, banning whoever submitted that entry.
+	admin.POST("/bans", func(c *gin.Context) {
+		type banRequest struct {
+			Kind    string `json:"kind"`
+			Value   string `json:"value"`
+			EntryID string `json:"entryId"`
+			Reason  string `json:"reason"`
+		}
+
+		var req banRequest
+		if err := c.ShouldBindJSON(&req); err != nil || (req.Kind != BanUsername && req.Kind != BanIdentity) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expected JSON body with kind (username|identity) and value or entryId"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		// Resolve the banned value from the entry when one is given
+		if req.EntryID != "" {
+			id, err := bson.ObjectIDFromHex(req.EntryID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "entryId must be a valid entry id"})
+				return
+			}
+			entry, err := GetLeaderboardEntry(mongoClient, id)
+			if errors.Is(err, ErrEntryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "leaderboard entry not found"})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up entry"})
+				return
+			}
+			req.Value = entry.Username
+			if req.Kind == BanIdentity {
+				req.Value = entry.Identity
+			}
+		}
+		if strings.TrimSpace(req.Value) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "nothing to ban: value is empty"})
+			return
+		}
+
+		ban, err := bans.Load().(*BanList).Add(mongoClient, Ban{
+			Kind:      req.Kind,
+			Value:     req.Value,
+			Reason:    req.Reason,
+			CreatedBy: c.GetString("admin"),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ban"})
+			return
+		}
+
+		if err := RecordAudit(mongoClient, c.GetString("admin"), "ban", ban.Kind+":"+ban.Value, req.Reason); err != nil {
+			log.Println("Failed to record audit entry:", err)
+		}
+		c.JSON(http.StatusCreated, ban)
+	})
+
+	// Lift a ban
+	admin.DELETE("/bans/:id", func(c *gin.Context) {
+		id, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a valid ban id"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		ban, err := bans.Load().(*BanList).Remove(mongoClient, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove ban"})
+			return
+		}
+		if ban == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ban not found"})
+			return
+		}
+
+		if err := RecordAudit(mongoClient, c.GetString("admin"), "unban", ban.Kind+":"+ban.Value, c.Query("reason")); err != nil {
+			log.Println("Failed to record audit entry:", err)
+		}
+		c.JSON(http.StatusOK, gin.H{"removed": ban})
+	})
+
+	// Schedule a season; the board switches over automatically when it starts
+	admin.POST("/seasons", func(c *gin.Context) {
+		var req Season
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expected JSON body with id, name, startAt and endAt (RFC 3339)"})
+			return
+		}
+		req.ID = strings.TrimSpace(req.ID)
+		req.Name = strings.TrimSpace(req.Name)
+		req.ArchivedAt = nil
+		if req.ID == "" || req.Name == "" || !req.EndAt.After(req.StartAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id and name are required and endAt must be after startAt"})
+			return
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		err := CreateSeason(mongoClient, req)
+		if errors.Is(err, ErrSeasonOverlap) || mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "season id is taken or its dates overlap another season"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create season"})
+			return
+		}
+
+		if err := RecordAudit(mongoClient, c.GetString("admin"), "create_season", req.ID, ""); err != nil {
+			log.Println("Failed to record audit entry:", err)
+		}
+		// Pick the season up right away if it has already started
+		if err := RefreshSeason(mongoClient, &season, &board); err != nil {
+			log.Println("Season refresh failed:", err)
+		}
+		c.JSON(http.StatusCreated, req)
+	})
+
+	// Read the moderation audit log
+	admin.GET("/audit", func(c *gin.Context) {
+		limit := 100
+		if l := c.Query("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n <= 0 || n > 1000 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be an integer between 1 and 1000"})
+				return
+			}
+			limit = n
+		}
+
+		val := client.Load()
+		if val == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database not ready"})
+			return
+		}
+		mongoClient := val.(*mongo.Client)
+
+		entries, err := GetAuditLog(mongoClient, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+			return
+		}
+		c.JSON(http.StatusOK, entries)
 	})
 
 	// Serve static files from the frontend build directory
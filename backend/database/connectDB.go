@@ -1,6 +1,6 @@
-/* Helper to establish an initial connection to the DB */
+/* Helper to establish an initial connection to the DB (shared by the server and tools) */
 
-package main
+package database
 
 // Necessary modules
 import (
@@ -20,10 +20,12 @@ import (
 
 // ConnectDB establishes a connection to MongoDB and returns the client
 func ConnectDB() (*mongo.Client, error) {
-	// Load .env file (supports running from backend/ or repo root) (only in development)
+	// Load .env file (supports running from backend/, backend/tools/ or repo root) (only in development)
 	if os.Getenv("FLY_APP_NAME") == "" {
-		if err := godotenv.Load(); err != nil {
-			_ = godotenv.Load("backend/.env")
+		for _, path := range []string{".env", "backend/.env", "tools/.env", "../.env"} {
+			if err := godotenv.Load(path); err == nil {
+				break
+			}
 		}
 	}
 
@@ -0,0 +1,251 @@
+/* Class/group leaderboards identified by short join codes */
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	groupCodeLength   = 6
+	groupCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I so codes read well aloud
+	groupDefaultTTL   = 180 * 24 * time.Hour               // Roughly a semester
+	groupMaxTTL       = 365 * 24 * time.Hour
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExpired  = errors.New("group has expired")
+)
+
+// Structure of a group document in MongoDB
+type Group struct {
+	Code           string    `bson:"code" json:"code"`
+	Name           string    `bson:"name" json:"name"`
+	GlobalBoard    bool      `bson:"global_board" json:"globalBoard"` // Whether member scores also post to the global board
+	CreatedAt      time.Time `bson:"created_at" json:"createdAt"`
+	ExpiresAt      time.Time `bson:"expires_at" json:"expiresAt"`
+	OwnerTokenHash string    `bson:"owner_token_hash" json:"-"`
+}
+
+// Expired reports whether the group no longer accepts scores or serves its board
+func (g *Group) Expired() bool {
+	return time.Now().After(g.ExpiresAt)
+}
+
+// Authorized reports whether token is the owner token issued when the group was created
+func (g *Group) Authorized(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(g.OwnerTokenHash)) == 1
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Random string drawn from alphabet using crypto/rand
+func randomString(alphabet string, n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = alphabet[int(buf[i])%len(alphabet)]
+	}
+	return string(buf), nil
+}
+
+// EnsureGroupIndexes creates the unique index on join codes
+func EnsureGroupIndexes(client *mongo.Client) error {
+	collection := client.Database("capymorphDB").Collection("groups")
+	_, err := collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// CreateGroup stores a new group with a fresh join code and returns it along with the
+// plaintext owner token (only its hash is persisted).
+func CreateGroup(client *mongo.Client, name string, globalBoard bool, ttl time.Duration) (*Group, string, error) {
+	collection := client.Database("capymorphDB").Collection("groups")
+
+	token, err := randomString(groupCodeAlphabet, 24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	group := Group{
+		Name:           name,
+		GlobalBoard:    globalBoard,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+		OwnerTokenHash: hashToken(token),
+	}
+
+	// Retry on the (unlikely) join code collision
+	for attempt := 0; attempt < 5; attempt++ {
+		group.Code, err = randomString(groupCodeAlphabet, groupCodeLength)
+		if err != nil {
+			return nil, "", err
+		}
+		_, err = collection.InsertOne(context.TODO(), group)
+		if err == nil {
+			return &group, token, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, "", err
+		}
+	}
+	return nil, "", err
+}
+
+// GetGroup looks up a group by join code, returning ErrGroupNotFound or ErrGroupExpired as appropriate
+func GetGroup(client *mongo.Client, code string) (*Group, error) {
+	collection := client.Database("capymorphDB").Collection("groups")
+
+	var group Group
+	err := collection.FindOne(context.TODO(), bson.M{"code": code}).Decode(&group)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if group.Expired() {
+		return &group, ErrGroupExpired
+	}
+	return &group, nil
+}
+
+// ExpireGroup closes a group immediately
+func ExpireGroup(client *mongo.Client, code string) error {
+	collection := client.Database("capymorphDB").Collection("groups")
+	_, err := collection.UpdateOne(context.TODO(), bson.M{"code": code}, bson.M{"$set": bson.M{"expires_at": time.Now().UTC()}})
+	return err
+}
+
+// RemoveGroupEntry takes an entry off a group's board. Entries that were only posted to the
+// group are deleted outright; entries also on the global board just lose their group tag.
+func RemoveGroupEntry(client *mongo.Client, code string, id bson.ObjectID) (bool, error) {
+	collection := client.Database("capymorphDB").Collection("leaderboards")
+
+	res, err := collection.DeleteOne(context.TODO(), bson.M{"_id": id, "group_code": code, "group_only": true})
+	if err != nil {
+		return false, err
+	}
+	if res.DeletedCount > 0 {
+		return true, nil
+	}
+
+	upd, err := collection.UpdateOne(context.TODO(), bson.M{"_id": id, "group_code": code}, bson.M{"$unset": bson.M{"group_code": ""}})
+	if err != nil {
+		return false, err
+	}
+	return upd.MatchedCount > 0, nil
+}
+
+// Boards of groups nobody has looked at for this long are evicted from the cache
+const groupBoardIdleTTL = 30 * time.Minute
+
+// A cached group board and when it was last used
+type groupBoard struct {
+	idx      *LeaderboardIndex
+	lastSeen time.Time
+}
+
+// GroupBoards lazily loads and caches one in-memory leaderboard index per group, evicting
+// boards left idle so old join codes don't stay in memory
+type GroupBoards struct {
+	mu        sync.Mutex
+	idleTTL   time.Duration
+	boards    map[string]*groupBoard
+	lastSweep time.Time
+}
+
+// NewGroupBoards creates an empty cache
+func NewGroupBoards() *GroupBoards {
+	return &GroupBoards{
+		idleTTL:   groupBoardIdleTTL,
+		boards:    make(map[string]*groupBoard),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the index for a group, loading it from MongoDB on first use
+func (gb *GroupBoards) Get(client *mongo.Client, code string) (*LeaderboardIndex, error) {
+	now := time.Now()
+
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+
+	gb.sweep(now)
+
+	if b, ok := gb.boards[code]; ok {
+		b.lastSeen = now
+		return b.idx, nil
+	}
+	idx, err := LoadLeaderboardIndex(client, bson.D{
+		{Key: "group_code", Value: code},
+		{Key: "hidden", Value: bson.D{{Key: "$ne", Value: true}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	gb.boards[code] = &groupBoard{idx: idx, lastSeen: now}
+	return idx, nil
+}
+
+// Drop boards that have been idle longer than idleTTL (at most once per TTL). A dropped
+// board is reloaded from MongoDB the next time it's asked for.
+func (gb *GroupBoards) sweep(now time.Time) {
+	if now.Sub(gb.lastSweep) < gb.idleTTL {
+		return
+	}
+	for code, b := range gb.boards {
+		if now.Sub(b.lastSeen) > gb.idleTTL {
+			delete(gb.boards, code)
+		}
+	}
+	gb.lastSweep = now
+}
+
+// Drop forgets a group's cached index (e.g. once it has expired)
+func (gb *GroupBoards) Drop(code string) {
+	gb.mu.Lock()
+	defer gb.mu.Unlock()
+	delete(gb.boards, code)
+}
+
+// Maps group lookup errors to HTTP responses, dropping cached boards of expired groups
+func respondGroupError(c *gin.Context, err error, groupBoards *GroupBoards, code string) {
+	switch {
+	case errors.Is(err, ErrGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no group with that join code"})
+	case errors.Is(err, ErrGroupExpired):
+		groupBoards.Drop(code)
+		c.JSON(http.StatusGone, gin.H{"error": "this group has expired"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up group"})
+	}
+}
+
+// Token from an "Authorization: Bearer <token>" header (empty if absent)
+func bearerToken(c *gin.Context) string {
+	return strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+}
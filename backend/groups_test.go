@@ -0,0 +1,50 @@
+/* Tests for the group board cache's idle eviction */
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupBoardsSweep(t *testing.T) {
+	tests := []struct {
+		name      string
+		idle      time.Duration // How long ago the board was last used
+		sinceLast time.Duration // How long ago the cache was last swept
+		wantKept  bool
+	}{
+		{"used recently", time.Minute, groupBoardIdleTTL, true},
+		{"idle past the TTL", groupBoardIdleTTL + time.Minute, groupBoardIdleTTL, false},
+		{"idle, but swept recently", groupBoardIdleTTL + time.Minute, time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			gb := NewGroupBoards()
+			gb.boards["ABC234"] = &groupBoard{idx: NewLeaderboardIndex(), lastSeen: now.Add(-tt.idle)}
+			gb.lastSweep = now.Add(-tt.sinceLast)
+
+			gb.sweep(now)
+			_, kept := gb.boards["ABC234"]
+			if kept != tt.wantKept {
+				t.Errorf("board kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestGroupBoardsGetRefreshes(t *testing.T) {
+	gb := NewGroupBoards()
+	idx := NewLeaderboardIndex()
+	gb.boards["ABC234"] = &groupBoard{idx: idx, lastSeen: time.Now().Add(-time.Hour)}
+
+	// A cached board is served without touching MongoDB, and counts as used again
+	got, err := gb.Get(nil, "ABC234")
+	if err != nil || got != idx {
+		t.Fatalf("Get = %p, %v, want the cached board", got, err)
+	}
+	if idle := time.Since(gb.boards["ABC234"].lastSeen); idle > time.Minute {
+		t.Errorf("board still looks idle for %v after Get", idle)
+	}
+}
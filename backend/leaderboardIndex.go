@@ -0,0 +1,295 @@
+/* In-memory ordered leaderboard index (indexable skip list) for cheap top-N and rank queries */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	skipMaxLevel = 32   // Enough levels for ~4^32 entries
+	skipP        = 0.25 // Probability of promoting a node one level up
+)
+
+// A skip list node. span[i] counts how many positions next[i] jumps over.
+type skipNode struct {
+	entry LeaderboardEntry
+	next  []*skipNode
+	span  []int
+}
+
+// LeaderboardIndex keeps every leaderboard entry ordered by score (desc), ties broken by
+// insertion order (ObjectID asc). Mongo remains the durable store; this is write-through.
+// Note: each server instance holds its own copy, so scores written by another instance
+// only show up here after a reload.
+type LeaderboardIndex struct {
+	mu     sync.RWMutex
+	head   *skipNode
+	level  int
+	length int
+	byID   map[bson.ObjectID]LeaderboardEntry
+	rnd    *rand.Rand
+	season string // season_id of the entries on the board ("" for the all-time board)
+}
+
+// Filter selecting the entries that belong on the global board (never hidden ones),
+// limited to one season unless seasonID is empty
+func globalBoardFilter(seasonID string) bson.D {
+	filter := bson.D{
+		{Key: "group_only", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "hidden", Value: bson.D{{Key: "$ne", Value: true}}},
+	}
+	if seasonID != "" {
+		filter = append(filter, bson.E{Key: "season_id", Value: seasonID})
+	}
+	return filter
+}
+
+// A window of the leaderboard centered on one entry
+type LeaderboardWindow struct {
+	Rank    int64              `json:"rank"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// NewLeaderboardIndex creates an empty index
+func NewLeaderboardIndex() *LeaderboardIndex {
+	return &LeaderboardIndex{
+		head:  &skipNode{next: make([]*skipNode, skipMaxLevel), span: make([]int, skipMaxLevel)},
+		level: 1,
+		byID:  make(map[bson.ObjectID]LeaderboardEntry),
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// LoadLeaderboardIndex builds an index from the leaderboards documents matching filter
+func LoadLeaderboardIndex(client *mongo.Client, filter bson.D) (*LeaderboardIndex, error) {
+	collection := client.Database("capymorphDB").Collection("leaderboards")
+
+	cursor, err := collection.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	idx := NewLeaderboardIndex()
+	for cursor.Next(context.TODO()) {
+		var entry LeaderboardEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, err
+		}
+		idx.Insert(entry)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// SeasonID is the season_id new entries on this board are tagged with
+func (idx *LeaderboardIndex) SeasonID() string {
+	return idx.season
+}
+
+// Ordering used by the list: higher score first, then older entry first
+func entryBefore(a, b LeaderboardEntry) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) < 0
+}
+
+func (idx *LeaderboardIndex) randomLevel() int {
+	lvl := 1
+	for lvl < skipMaxLevel && idx.rnd.Float64() < skipP {
+		lvl++
+	}
+	return lvl
+}
+
+// Insert adds an entry (no-op if an entry with the same ID is already indexed)
+func (idx *LeaderboardIndex) Insert(entry LeaderboardEntry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, exists := idx.byID[entry.ID]; exists {
+		return
+	}
+
+	var update [skipMaxLevel]*skipNode
+	var rank [skipMaxLevel]int
+
+	// Find the predecessor at every level, tracking the position reached
+	x := idx.head
+	for i := idx.level - 1; i >= 0; i-- {
+		if i < idx.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i] != nil && entryBefore(x.next[i].entry, entry) {
+			rank[i] += x.span[i]
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+
+	lvl := idx.randomLevel()
+	if lvl > idx.level {
+		for i := idx.level; i < lvl; i++ {
+			rank[i] = 0
+			update[i] = idx.head
+			update[i].span[i] = idx.length
+		}
+		idx.level = lvl
+	}
+
+	node := &skipNode{entry: entry, next: make([]*skipNode, lvl), span: make([]int, lvl)}
+	for i := 0; i < lvl; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+		node.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	// Levels above the new node now skip over one more position
+	for i := lvl; i < idx.level; i++ {
+		update[i].span[i]++
+	}
+
+	idx.length++
+	idx.byID[entry.ID] = entry
+}
+
+// Remove deletes the entry with the given ID, reporting whether it was present
+func (idx *LeaderboardIndex) Remove(id bson.ObjectID) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	entry, ok := idx.byID[id]
+	if !ok {
+		return false
+	}
+
+	var update [skipMaxLevel]*skipNode
+	x := idx.head
+	for i := idx.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && entryBefore(x.next[i].entry, entry) {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+
+	target := x.next[0]
+	for i := 0; i < idx.level; i++ {
+		if update[i].next[i] == target {
+			update[i].span[i] += target.span[i] - 1
+			update[i].next[i] = target.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	for idx.level > 1 && idx.head.next[idx.level-1] == nil {
+		idx.level--
+	}
+
+	idx.length--
+	delete(idx.byID, id)
+	return true
+}
+
+// Len returns the number of indexed entries
+func (idx *LeaderboardIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.length
+}
+
+// Top returns the first n entries in leaderboard order
+func (idx *LeaderboardIndex) Top(n int) []LeaderboardEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	out := make([]LeaderboardEntry, 0, min(n, idx.length))
+	for x := idx.head.next[0]; x != nil && len(out) < n; x = x.next[0] {
+		out = append(out, x.entry)
+	}
+	return out
+}
+
+// RankOf returns the competition rank a score would have (1 + entries with a strictly higher score)
+func (idx *LeaderboardIndex) RankOf(score int) int64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return int64(idx.countAbove(score)) + 1
+}
+
+// Around returns the entry with the given ID plus up to `radius` neighbours on each side
+func (idx *LeaderboardIndex) Around(id bson.ObjectID, radius int) (*LeaderboardWindow, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entry, ok := idx.byID[id]
+	if !ok {
+		return nil, false
+	}
+
+	pos := idx.positionOf(entry)
+	start := max(1, pos-radius)
+	window := &LeaderboardWindow{
+		Rank:    int64(idx.countAbove(entry.Score)) + 1,
+		Entries: make([]LeaderboardEntry, 0, 2*radius+1),
+	}
+	for x := idx.nodeAt(start); x != nil && len(window.Entries) < pos+radius-start+1; x = x.next[0] {
+		window.Entries = append(window.Entries, x.entry)
+	}
+	return window, true
+}
+
+// Number of entries with a score strictly greater than score (caller holds the lock)
+func (idx *LeaderboardIndex) countAbove(score int) int {
+	count := 0
+	x := idx.head
+	for i := idx.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i].entry.Score > score {
+			count += x.span[i]
+			x = x.next[i]
+		}
+	}
+	return count
+}
+
+// 1-based position of an indexed entry (caller holds the lock)
+func (idx *LeaderboardIndex) positionOf(entry LeaderboardEntry) int {
+	pos := 0
+	x := idx.head
+	for i := idx.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && entryBefore(x.next[i].entry, entry) {
+			pos += x.span[i]
+			x = x.next[i]
+		}
+	}
+	return pos + 1
+}
+
+// Node at a 1-based position, or nil if out of range (caller holds the lock)
+func (idx *LeaderboardIndex) nodeAt(pos int) *skipNode {
+	if pos < 1 || pos > idx.length {
+		return nil
+	}
+	traversed := 0
+	x := idx.head
+	for i := idx.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && traversed+x.span[i] <= pos {
+			traversed += x.span[i]
+			x = x.next[i]
+		}
+		if traversed == pos {
+			return x
+		}
+	}
+	return nil
+}
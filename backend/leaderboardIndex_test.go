@@ -0,0 +1,199 @@
+/* Tests for the in-memory leaderboard index: ordering, ties, ranks and windows */
+
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// An ObjectID that sorts by n, so insertion order is explicit
+func entryID(n int) bson.ObjectID {
+	var id bson.ObjectID
+	id[10], id[11] = byte(n>>8), byte(n)
+	return id
+}
+
+func entry(n, score int) LeaderboardEntry {
+	return LeaderboardEntry{ID: entryID(n), Score: score}
+}
+
+// The IDs of entries, as the numbers they were made from
+func entryNumbers(entries []LeaderboardEntry) []int {
+	var out []int
+	for _, e := range entries {
+		out = append(out, int(e.ID[10])<<8|int(e.ID[11]))
+	}
+	return out
+}
+
+// 100 (#5), 80 (#2), 80 (#3), 50 (#1), 20 (#4): a tie in the middle, inserted out of order
+func sampleIndex() *LeaderboardIndex {
+	idx := NewLeaderboardIndex()
+	for _, e := range []LeaderboardEntry{entry(3, 80), entry(1, 50), entry(5, 100), entry(4, 20), entry(2, 80)} {
+		idx.Insert(e)
+	}
+	return idx
+}
+
+func TestLeaderboardIndexInsert(t *testing.T) {
+	idx := sampleIndex()
+	idx.Insert(entry(2, 10)) // Same ID: ignored, even with another score
+
+	if got := idx.Len(); got != 5 {
+		t.Fatalf("Len() = %d, want 5", got)
+	}
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{0, nil},
+		{1, []int{5}},
+		{3, []int{5, 2, 3}},
+		{5, []int{5, 2, 3, 1, 4}},
+		{10, []int{5, 2, 3, 1, 4}},
+	}
+	for _, tt := range tests {
+		if got := entryNumbers(idx.Top(tt.n)); !slices.Equal(got, tt.want) {
+			t.Errorf("Top(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLeaderboardIndexRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove []int
+		want   []int
+	}{
+		{"first", []int{5}, []int{2, 3, 1, 4}},
+		{"last", []int{4}, []int{5, 2, 3, 1}},
+		{"older of a tie", []int{2}, []int{5, 3, 1, 4}},
+		{"newer of a tie", []int{3}, []int{5, 2, 1, 4}},
+		{"everything", []int{1, 2, 3, 4, 5}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := sampleIndex()
+			for _, n := range tt.remove {
+				if !idx.Remove(entryID(n)) {
+					t.Fatalf("Remove(#%d) = false, want true", n)
+				}
+			}
+			if got := entryNumbers(idx.Top(10)); !slices.Equal(got, tt.want) {
+				t.Errorf("Top(10) = %v, want %v", got, tt.want)
+			}
+			if got := idx.Len(); got != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", got, len(tt.want))
+			}
+			if idx.Remove(entryID(tt.remove[0])) {
+				t.Errorf("removing #%d twice = true, want false", tt.remove[0])
+			}
+		})
+	}
+}
+
+func TestLeaderboardIndexRankOf(t *testing.T) {
+	idx := sampleIndex()
+	tests := []struct {
+		score int
+		want  int64
+	}{
+		{200, 1}, // Above everyone
+		{100, 1}, // Ties the leader
+		{90, 2},
+		{80, 2}, // Ties share the better rank
+		{79, 4},
+		{50, 4},
+		{20, 5}, // Ties the last entry
+		{0, 6},  // Below everyone
+	}
+	for _, tt := range tests {
+		if got := idx.RankOf(tt.score); got != tt.want {
+			t.Errorf("RankOf(%d) = %d, want %d", tt.score, got, tt.want)
+		}
+	}
+
+	if got := NewLeaderboardIndex().RankOf(50); got != 1 {
+		t.Errorf("RankOf on an empty board = %d, want 1", got)
+	}
+}
+
+func TestLeaderboardIndexAround(t *testing.T) {
+	idx := sampleIndex()
+	tests := []struct {
+		name     string
+		n        int
+		radius   int
+		wantRank int64
+		want     []int
+	}{
+		{"first entry", 5, 2, 1, []int{5, 2, 3}},
+		{"last entry", 4, 2, 5, []int{3, 1, 4}},
+		{"middle", 1, 1, 4, []int{3, 1, 4}},
+		{"tied, newer", 3, 1, 2, []int{2, 3, 1}},
+		{"tied, older", 2, 0, 2, []int{2}},
+		{"radius past both ends", 3, 10, 2, []int{5, 2, 3, 1, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, ok := idx.Around(entryID(tt.n), tt.radius)
+			if !ok {
+				t.Fatalf("Around(#%d) found nothing", tt.n)
+			}
+			if window.Rank != tt.wantRank {
+				t.Errorf("rank = %d, want %d", window.Rank, tt.wantRank)
+			}
+			if got := entryNumbers(window.Entries); !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := idx.Around(entryID(99), 2); ok {
+		t.Error("Around found an entry that was never inserted")
+	}
+}
+
+// Many inserts and removes keep the skip list's spans consistent with a plain sorted slice
+func TestLeaderboardIndexMatchesSort(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	idx := NewLeaderboardIndex()
+	var all []LeaderboardEntry
+	for n := 0; n < 1000; n++ {
+		e := entry(n, rng.Intn(50))
+		idx.Insert(e)
+		all = append(all, e)
+	}
+	for _, i := range rng.Perm(len(all))[:500] {
+		idx.Remove(all[i].ID)
+		all[i].Score = -1
+	}
+	var kept []LeaderboardEntry
+	for _, e := range all {
+		if e.Score >= 0 {
+			kept = append(kept, e)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return entryBefore(kept[i], kept[j]) })
+
+	if got, want := entryNumbers(idx.Top(len(kept))), entryNumbers(kept); !slices.Equal(got, want) {
+		t.Fatal("Top order differs from sorting the entries")
+	}
+	for pos, e := range kept {
+		window, _ := idx.Around(e.ID, 0)
+		above := 0
+		for _, other := range kept {
+			if other.Score > e.Score {
+				above++
+			}
+		}
+		if window.Rank != int64(above)+1 || window.Entries[0].ID != e.ID {
+			t.Fatalf("entry at position %d: rank %d, want %d", pos+1, window.Rank, above+1)
+		}
+	}
+}
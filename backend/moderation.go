@@ -0,0 +1,291 @@
+/* Leaderboard moderation: admin auth, hiding/deleting entries, bans and the audit log */
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Ban kinds
+const (
+	BanUsername = "username" // Case-insensitive username
+	BanIdentity = "identity" // Hashed client IP recorded on submissions
+)
+
+var ErrEntryNotFound = errors.New("leaderboard entry not found")
+
+// Structure of a ban document in MongoDB
+type Ban struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind      string        `bson:"kind" json:"kind"`
+	Value     string        `bson:"value" json:"value"`
+	Reason    string        `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy string        `bson:"created_by" json:"createdBy"`
+	CreatedAt time.Time     `bson:"created_at" json:"createdAt"`
+}
+
+// Structure of an audit log document in MongoDB
+type AuditEntry struct {
+	ID     bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Actor  string        `bson:"actor" json:"actor"`   // Admin name, or "group:<code>" for group owners
+	Action string        `bson:"action" json:"action"` // hide|unhide|delete|ban|unban|group_remove|group_close|create_season
+	Target string        `bson:"target" json:"target"`
+	Reason string        `bson:"reason,omitempty" json:"reason,omitempty"`
+	At     time.Time     `bson:"at" json:"at"`
+}
+
+// Hash a client IP so bans can target it without storing raw addresses
+func identityOf(ip string) string {
+	return hashToken(ip)
+}
+
+// Admin name -> token, parsed from ADMIN_TOKENS ("alice:token1,bob:token2")
+func loadAdminTokens() map[string]string {
+	tokens := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("ADMIN_TOKENS"), ",") {
+		name, token, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || name == "" || token == "" {
+			continue
+		}
+		tokens[name] = token
+	}
+	return tokens
+}
+
+// RequireAdmin authenticates a bearer token against ADMIN_TOKENS and stores the admin's name
+// under "admin" in the context. With no tokens configured the admin API is disabled.
+func RequireAdmin() gin.HandlerFunc {
+	tokens := loadAdminTokens()
+	return func(c *gin.Context) {
+		if len(tokens) == 0 {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "admin API is not enabled"})
+			return
+		}
+		given := bearerToken(c)
+		for name, token := range tokens {
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+				c.Set("admin", name)
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+	}
+}
+
+// RecordAudit appends a moderation action to the audit log
+func RecordAudit(client *mongo.Client, actor, action, target, reason string) error {
+	collection := client.Database("capymorphDB").Collection("moderation_audit")
+	_, err := collection.InsertOne(context.TODO(), AuditEntry{
+		Actor:  actor,
+		Action: action,
+		Target: target,
+		Reason: reason,
+		At:     time.Now().UTC(),
+	})
+	return err
+}
+
+// GetAuditLog returns the most recent audit entries, newest first
+func GetAuditLog(client *mongo.Client, limit int) ([]AuditEntry, error) {
+	collection := client.Database("capymorphDB").Collection("moderation_audit")
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "at", Value: -1}})
+	findOptions.SetLimit(int64(limit))
+
+	cursor, err := collection.Find(context.TODO(), bson.D{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	entries := []AuditEntry{}
+	if err := cursor.All(context.TODO(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetLeaderboardEntry fetches a single entry by id (hidden or not)
+func GetLeaderboardEntry(client *mongo.Client, id bson.ObjectID) (*LeaderboardEntry, error) {
+	collection := client.Database("capymorphDB").Collection("leaderboards")
+
+	var entry LeaderboardEntry
+	err := collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// SetEntryHidden hides or restores an entry in MongoDB and returns the updated entry
+func SetEntryHidden(client *mongo.Client, id bson.ObjectID, hidden bool) (*LeaderboardEntry, error) {
+	collection := client.Database("capymorphDB").Collection("leaderboards")
+
+	update := bson.M{"$unset": bson.M{"hidden": ""}}
+	if hidden {
+		update = bson.M{"$set": bson.M{"hidden": true}}
+	}
+
+	var entry LeaderboardEntry
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := collection.FindOneAndUpdate(context.TODO(), bson.M{"_id": id}, update, opts).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DeleteLeaderboardEntry permanently removes an entry and returns what was deleted
+func DeleteLeaderboardEntry(client *mongo.Client, id bson.ObjectID) (*LeaderboardEntry, error) {
+	collection := client.Database("capymorphDB").Collection("leaderboards")
+
+	var entry LeaderboardEntry
+	err := collection.FindOneAndDelete(context.TODO(), bson.M{"_id": id}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// BanList is an in-memory mirror of the bans collection, checked on every submission
+type BanList struct {
+	mu   sync.RWMutex
+	bans map[string]Ban // keyed by kind + ":" + value
+}
+
+func banKey(kind, value string) string {
+	return kind + ":" + value
+}
+
+// Normalize ban values so lookups are case-insensitive for usernames
+func normalizeBanValue(kind, value string) string {
+	value = strings.TrimSpace(value)
+	if kind == BanUsername {
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
+// LoadBanList reads every ban from MongoDB
+func LoadBanList(client *mongo.Client) (*BanList, error) {
+	collection := client.Database("capymorphDB").Collection("bans")
+
+	cursor, err := collection.Find(context.TODO(), bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var bans []Ban
+	if err := cursor.All(context.TODO(), &bans); err != nil {
+		return nil, err
+	}
+
+	list := &BanList{bans: make(map[string]Ban, len(bans))}
+	for _, b := range bans {
+		list.bans[banKey(b.Kind, b.Value)] = b
+	}
+	return list, nil
+}
+
+// Banned reports whether a submission from this username/identity is blocked
+func (bl *BanList) Banned(username, identity string) bool {
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	_, nameBanned := bl.bans[banKey(BanUsername, normalizeBanValue(BanUsername, username))]
+	_, idBanned := bl.bans[banKey(BanIdentity, identity)]
+	return nameBanned || idBanned
+}
+
+// All returns every active ban
+func (bl *BanList) All() []Ban {
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	out := make([]Ban, 0, len(bl.bans))
+	for _, b := range bl.bans {
+		out = append(out, b)
+	}
+	return out
+}
+
+// EnsureBanIndexes creates the unique index on (kind, value), so each user has at most one ban
+func EnsureBanIndexes(client *mongo.Client) error {
+	collection := client.Database("capymorphDB").Collection("bans")
+	_, err := collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "value", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Add persists a ban and mirrors it in memory. Banning someone who is already banned keeps
+// the existing ban and returns it.
+func (bl *BanList) Add(client *mongo.Client, ban Ban) (*Ban, error) {
+	collection := client.Database("capymorphDB").Collection("bans")
+
+	ban.Value = normalizeBanValue(ban.Kind, ban.Value)
+	filter := bson.D{{Key: "kind", Value: ban.Kind}, {Key: "value", Value: ban.Value}}
+	update := bson.M{"$setOnInsert": bson.M{
+		"_id":        bson.NewObjectID(),
+		"reason":     ban.Reason,
+		"created_by": ban.CreatedBy,
+		"created_at": time.Now().UTC(),
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored Ban
+	if err := collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&stored); err != nil {
+		return nil, err
+	}
+
+	bl.mu.Lock()
+	bl.bans[banKey(stored.Kind, stored.Value)] = stored
+	bl.mu.Unlock()
+	return &stored, nil
+}
+
+// Remove lifts a ban by id, returning it (nil if no such ban)
+func (bl *BanList) Remove(client *mongo.Client, id bson.ObjectID) (*Ban, error) {
+	collection := client.Database("capymorphDB").Collection("bans")
+
+	var ban Ban
+	err := collection.FindOneAndDelete(context.TODO(), bson.M{"_id": id}).Decode(&ban)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Clear duplicates left from before the unique index, or they would ban again on restart
+	if _, err := collection.DeleteMany(context.TODO(), bson.M{"kind": ban.Kind, "value": ban.Value}); err != nil {
+		return nil, err
+	}
+
+	bl.mu.Lock()
+	delete(bl.bans, banKey(ban.Kind, ban.Value))
+	bl.mu.Unlock()
+	return &ban, nil
+}
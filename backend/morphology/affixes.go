@@ -0,0 +1,428 @@
+/* Declarative affix rules: the generator's affixes are data, loaded from JSON rule files */
+
+package morphology
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// An affix as described in a rule file, e.g.
+//
+//	{"name": "ness", "surface": "ness", "position": "suffix", "type": "derivational",
+//	 "input": ["adjective"], "output": "noun", "spelling": [{"endings": ["y"], "drop": 1, "append": "i"}]}
+type AffixRule struct {
+	Name           string            `json:"name"`                      // How the generator refers to it (plural, er, un...)
+	Surface        string            `json:"surface"`                   // Default spelling
+	Position       string            `json:"position"`                  // prefix|suffix
+	Type           string            `json:"type"`                      // inflectional|derivational
+	Input          []string          `json:"input"`                     // Categories it attaches to (its selectional restriction)
+	Output         string            `json:"output,omitempty"`          // Category of the result (empty keeps the input's)
+	Meanings       map[string]string `json:"meanings,omitempty"`        // Input category -> what the affix means there
+	Features       map[string]string `json:"features,omitempty"`        // Features of the affix morpheme
+	WordFeatures   map[string]string `json:"word_features,omitempty"`   // Features of the resulting word
+	Attested       bool              `json:"attested,omitempty"`        // Only attach to words whose entry lists this affix
+	Base           *BaseShape        `json:"base,omitempty"`            // Further restrictions on the base's shape
+	Gloss          string            `json:"gloss,omitempty"`           // What an inflection encodes when the template files have no gloss.<name> label ("tense: past")
+	Function       string            `json:"function,omitempty"`        // What the affix does, to tell look-alike affixes apart ("agentive" vs "comparative" -er)
+	Irregular      string            `json:"irregular,omitempty"`       // Lexicon form that overrides the rule: past|plural|comparative|superlative
+	IrregularLabel string            `json:"irregular_label,omitempty"` // Morpheme shown for irregular forms (PST, PL)
+	TagAllomorph   bool              `json:"tag_allomorph,omitempty"`   // Record the allomorph used in the morpheme features
+	Spelling       []SpellingRule    `json:"spelling,omitempty"`        // Allomorphy and spelling changes; the first match wins
+	Phonology      []PhonologyRule   `json:"phonology,omitempty"`       // How the suffix sounds after the base's final sound; the first match wins
+}
+
+// A pronunciation of a suffix, chosen by the last phone of the base (ARPAbet, no stress
+// marks). A rule with no phones listed matches any base.
+type PhonologyRule struct {
+	After []string `json:"after,omitempty"` // Final phones of the base this applies after (S Z SH ZH CH JH)
+	IPA   string   `json:"ipa"`             // The suffix's pronunciation (ɪz)
+}
+
+// A spelling change triggered by how the base ends. In endings, "C" stands for any
+// consonant and "V" for any vowel; other letters match themselves ("Cy" matches "carry").
+type SpellingRule struct {
+	Endings      []string `json:"endings"`                 // The rule applies when the base ends with one of these
+	Except       []string `json:"except,omitempty"`        // ...unless it ends with one of these
+	MaxSyllables int      `json:"max_syllables,omitempty"` // ...and only for bases this short (stop -> stopped, but visit -> visited)
+	Drop         int      `json:"drop,omitempty"`          // Letters removed from the end of the base
+	Append       string   `json:"append,omitempty"`        // Letters added to the stem after dropping (carry -> carri)
+	Double       bool     `json:"double,omitempty"`        // Double the base's final letter (stop -> stopp)
+	Surface      string   `json:"surface,omitempty"`       // Allomorph used instead of the default surface
+}
+
+// Restrictions on the shape of a base, beyond its category
+type BaseShape struct {
+	MaxSyllables int      `json:"max_syllables,omitempty"` // Longer bases are left out (more curious, not curiouser)...
+	Endings      []string `json:"endings,omitempty"`       // ...unless they end with one of these (happier)
+}
+
+// AffixSet is an ordered collection of affix rules, looked up by name
+type AffixSet struct {
+	rules []AffixRule
+}
+
+//go:embed data/affixes.json
+var builtinAffixes []byte
+
+// DefaultAffixes returns the built-in rules (plural, past, -er, -ness, -ful, -less, -able,
+// -ment, -tion and the un-/re-/pre-/dis-/mis- prefixes)
+func DefaultAffixes() *AffixSet {
+	rules, err := parseAffixRules(builtinAffixes)
+	if err != nil {
+		panic("morphology: bad built-in affix rules: " + err.Error())
+	}
+	return &AffixSet{rules: rules}
+}
+
+// LoadAffixRules reads a rule file on top of the built-in rules: a rule with a built-in
+// name replaces it, any other rule is added.
+func LoadAffixRules(path string) (*AffixSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseAffixRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	set := DefaultAffixes()
+	for _, r := range rules {
+		if i := set.index(r.Name); i >= 0 {
+			set.rules[i] = r
+		} else {
+			set.rules = append(set.rules, r)
+		}
+	}
+	return set, nil
+}
+
+func parseAffixRules(data []byte) ([]AffixRule, error) {
+	var file struct {
+		Affixes []AffixRule `json:"affixes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, r := range file.Affixes {
+		if err := r.check(); err != nil {
+			return nil, err
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("affix %q is defined twice", r.Name)
+		}
+		seen[r.Name] = true
+	}
+	return file.Affixes, nil
+}
+
+// Catch rule file mistakes at load time rather than as odd questions
+func (r AffixRule) check() error {
+	if r.Name == "" || r.Surface == "" {
+		return fmt.Errorf("affix %q: name and surface are required", r.Name)
+	}
+	if r.Position != "prefix" && r.Position != "suffix" {
+		return fmt.Errorf("affix %q: position must be prefix or suffix, got %q", r.Name, r.Position)
+	}
+	if r.Type != "inflectional" && r.Type != "derivational" {
+		return fmt.Errorf("affix %q: type must be inflectional or derivational, got %q", r.Name, r.Type)
+	}
+	if len(r.Input) == 0 {
+		return fmt.Errorf("affix %q: input needs at least one category", r.Name)
+	}
+	categories := []string{POSNoun, POSVerb, POSAdjective}
+	for _, c := range append(slices.Clone(r.Input), r.Output) {
+		if c != "" && !slices.Contains(categories, c) {
+			return fmt.Errorf("affix %q: unknown category %q", r.Name, c)
+		}
+	}
+	for c := range r.Meanings {
+		if !slices.Contains(r.Input, c) {
+			return fmt.Errorf("affix %q: meaning given for %q, which is not an input category", r.Name, c)
+		}
+	}
+	if r.Irregular != "" && !slices.Contains([]string{"past", "plural", "comparative", "superlative"}, r.Irregular) {
+		return fmt.Errorf("affix %q: irregular must be past, plural, comparative or superlative, got %q", r.Name, r.Irregular)
+	}
+	if r.Position == "prefix" && len(r.Spelling) > 0 {
+		return fmt.Errorf("affix %q: spelling rules are only supported on suffixes", r.Name)
+	}
+	if r.Position == "prefix" && len(r.Phonology) > 0 {
+		return fmt.Errorf("affix %q: phonology rules are only supported on suffixes", r.Name)
+	}
+	for _, ph := range r.Phonology {
+		if ph.IPA == "" {
+			return fmt.Errorf("affix %q: every phonology rule needs an ipa", r.Name)
+		}
+	}
+	return nil
+}
+
+func (s *AffixSet) index(name string) int {
+	return slices.IndexFunc(s.rules, func(r AffixRule) bool { return r.Name == name })
+}
+
+// Rule looks up an affix by name
+func (s *AffixSet) Rule(name string) (AffixRule, bool) {
+	if i := s.index(name); i >= 0 {
+		return s.rules[i], true
+	}
+	return AffixRule{}, false
+}
+
+// Rules lists every affix in file order
+func (s *AffixSet) Rules() []AffixRule {
+	return slices.Clone(s.rules)
+}
+
+// Positioned lists the affixes at one position (prefix or suffix)
+func (s *AffixSet) Positioned(position string) []AffixRule {
+	var out []AffixRule
+	for _, r := range s.rules {
+		if r.Position == position {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// The affix at position spelled surface (or one of its allomorphs), preferring one that
+// attaches to category: -er on a verb is agentive, on an adjective comparative
+func (s *AffixSet) spelled(position, surface, category string) (AffixRule, bool) {
+	var found []AffixRule
+	for _, r := range s.Positioned(position) {
+		if r.Surface == surface || slices.ContainsFunc(r.Spelling, func(sp SpellingRule) bool { return sp.Surface == surface }) {
+			found = append(found, r)
+		}
+	}
+	if len(found) == 0 {
+		return AffixRule{}, false
+	}
+	for _, r := range found {
+		if r.Attaches(category) {
+			return r, true
+		}
+	}
+	return found[0], true
+}
+
+// Attaches reports whether the affix's selectional restriction allows a base of this category
+func (r AffixRule) Attaches(category string) bool {
+	return slices.Contains(r.Input, category)
+}
+
+// Accepts reports whether the affix can attach to word, a base of category: its selectional
+// restriction and any limit on the base's shape
+func (r AffixRule) Accepts(category, word string) bool {
+	if !r.Attaches(category) {
+		return false
+	}
+	if r.Base != nil && r.Base.MaxSyllables > 0 && syllables(word) > r.Base.MaxSyllables {
+		return endsWithAny(word, r.Base.Endings)
+	}
+	return true
+}
+
+// means reports whether the affix has this meaning on any category
+func (r AffixRule) means(meaning string) bool {
+	return slices.Contains(slices.Collect(maps.Values(r.Meanings)), meaning)
+}
+
+// OutputOf is the category of a word built on a base of this category
+func (r AffixRule) OutputOf(category string) string {
+	if r.Output == "" {
+		return category
+	}
+	return r.Output
+}
+
+// Apply attaches the affix named name to base. It doesn't check the selectional restriction,
+// so it can also build ill-formed words on purpose.
+func (g *MorphGenerator) Apply(base WordForm, name string) WordForm {
+	rule, ok := g.affixes.Rule(name)
+	if !ok {
+		panic("morphology: unknown affix " + name)
+	}
+
+	features := map[string]string{}
+	maps.Copy(features, rule.Features)
+	if rule.Type == "derivational" {
+		if _, ok := features["derivation"]; !ok {
+			features["derivation"] = shortCategory(base.Category) + "->" + shortCategory(rule.OutputOf(base.Category))
+		}
+		if meaning, ok := rule.Meanings[base.Category]; ok {
+			features["meaning"] = meaning
+		}
+	}
+
+	out := base
+	out.Category = rule.OutputOf(base.Category)
+	out.Features = map[string]string{}
+	maps.Copy(out.Features, rule.WordFeatures)
+
+	affix := Morpheme{Surface: rule.Surface, Role: "affix", Bound: true, MorphType: rule.Type, Position: rule.Position, Features: features}
+	if irr, ok := g.irregularForm(rule, base.Surface); ok {
+		// Irregular forms replace the whole word and have no position
+		out.Surface = irr
+		affix.Surface = rule.IrregularLabel
+		affix.Position = ""
+		features["allomorph"] = "irregular"
+		if class, ok := g.lex.IrregularClassOf(base.Surface, rule.Irregular); ok {
+			features["irregular"] = class
+		}
+		out.Morphemes = append(slices.Clone(base.Morphemes), affix)
+		out.Tree = &TreeNode{Surface: out.Surface, Category: out.Category, Children: []*TreeNode{
+			base.tree(), {Surface: affix.Surface, Role: "affix", Affix: rule.Name},
+		}}
+		return out
+	}
+
+	stem, surface := rule.spell(base.Surface, base.unprefixed())
+	affix.Surface = surface
+	if rule.TagAllomorph {
+		features["allomorph"] = surface
+	}
+	if phones, ok := g.Pronounce(base.Surface); ok {
+		if sound, ok := rule.soundAfter(phones); ok {
+			features["pronunciation"] = "/" + sound + "/"
+		}
+	}
+	leaf := &TreeNode{Surface: surface, Role: "affix", Affix: rule.Name}
+	if rule.Position == "prefix" {
+		out.Surface = surface + stem
+		out.Morphemes = append([]Morpheme{affix}, base.Morphemes...)
+		out.Tree = &TreeNode{Children: []*TreeNode{leaf, base.tree()}}
+	} else {
+		out.Surface = stem + surface
+		out.Morphemes = append(slices.Clone(base.Morphemes), affix)
+		out.Tree = &TreeNode{Children: []*TreeNode{base.tree(), leaf}}
+	}
+	out.Tree.Surface, out.Tree.Category = out.Surface, out.Category
+	return out
+}
+
+// The word without its prefixes. Syllables are counted on it, so replan doubles like plan
+// (replanned).
+func (w WordForm) unprefixed() string {
+	root := w.Surface
+	for _, m := range w.Morphemes {
+		if m.Position != "prefix" {
+			break
+		}
+		root = strings.TrimPrefix(root, m.Surface)
+	}
+	return root
+}
+
+func (g *MorphGenerator) irregularForm(rule AffixRule, word string) (string, bool) {
+	switch rule.Irregular {
+	case "past":
+		return g.lex.IrregularPast(word)
+	case "plural":
+		return g.lex.IrregularPlural(word)
+	case "comparative":
+		cmp, _, ok := g.lex.IrregularComparative(word)
+		return cmp, ok
+	case "superlative":
+		_, sup, ok := g.lex.IrregularComparative(word)
+		return sup, ok && sup != ""
+	}
+	return "", false
+}
+
+// The base's spelling before the affix, and the affix allomorph, after the first matching rule.
+// root is the base without its prefixes, which is what max_syllables counts.
+func (r AffixRule) spell(base, root string) (string, string) {
+	if i, ok := r.spellingFor(base, root); ok {
+		return r.Spelling[i].apply(base, r.Surface)
+	}
+	return base, r.Surface
+}
+
+// Index of the first spelling rule that matches the base
+func (r AffixRule) spellingFor(base, root string) (int, bool) {
+	for i, s := range r.Spelling {
+		if !endsWithAny(base, s.Endings) || endsWithAny(base, s.Except) {
+			continue
+		}
+		if s.MaxSyllables > 0 && syllables(root) > s.MaxSyllables {
+			continue
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// The stem and allomorph a spelling rule gives the base, whether or not it matches
+func (s SpellingRule) apply(base, surface string) (string, string) {
+	stem := base
+	if s.Double && stem != "" {
+		stem += stem[len(stem)-1:]
+	}
+	stem = stem[:max(len(stem)-s.Drop, 0)] + s.Append
+	if s.Surface != "" {
+		surface = s.Surface
+	}
+	return stem, surface
+}
+
+func endsWithAny(word string, patterns []string) bool {
+	return slices.ContainsFunc(patterns, func(p string) bool { return endsWith(word, p) })
+}
+
+// Match a word ending against a pattern where C is any consonant and V any vowel
+func endsWith(word, pattern string) bool {
+	if len(word) < len(pattern) {
+		return false
+	}
+	tail := word[len(word)-len(pattern):]
+	for i := 0; i < len(pattern); i++ {
+		switch p, c := pattern[i], tail[i]; p {
+		case 'C':
+			if isVowel(c) {
+				return false
+			}
+		case 'V':
+			if !isVowel(c) {
+				return false
+			}
+		default:
+			if p != c {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Rough syllable count: vowel groups (y counts after the first letter), less a silent final e
+func syllables(word string) int {
+	count := 0
+	inVowel := false
+	for i := 0; i < len(word); i++ {
+		v := isVowel(word[i]) || (word[i] == 'y' && i > 0)
+		if v && !inVowel {
+			count++
+		}
+		inVowel = v
+	}
+	if count > 1 && endsWith(word, "Ce") && !endsWith(word, "le") {
+		count--
+	}
+	return max(count, 1)
+}
+
+// Categories as written in derivation features (adj->noun)
+func shortCategory(category string) string {
+	if category == POSAdjective {
+		return "adj"
+	}
+	return category
+}
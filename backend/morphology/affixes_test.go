@@ -0,0 +1,91 @@
+/* Tests for affix spelling rules: consonant doubling, silent-e, y -> i and allomorphs */
+
+package morphology
+
+import "testing"
+
+func TestAffixRuleSpelling(t *testing.T) {
+	affixes := DefaultAffixes()
+	tests := []struct {
+		affix     string
+		base      string
+		root      string // The base without prefixes ("" when it has none)
+		wantStem  string
+		wantAffix string
+	}{
+		// Doubling: short bases ending consonant-vowel-consonant
+		{"past", "stop", "", "stopp", "ed"},
+		{"progressive", "run", "", "runn", "ing"},
+		{"comparative", "big", "", "bigg", "er"},
+		{"superlative", "wet", "", "wett", "est"},
+		{"er", "run", "", "runn", "er"},
+		{"past", "replan", "plan", "replann", "ed"}, // Syllables are counted without the prefix
+		{"past", "visit", "", "visit", "ed"},
+		{"past", "play", "", "play", "ed"},
+		{"comparative", "fast", "", "fast", "er"},
+
+		// Silent e: dropped before a vowel, or the suffix loses its own e
+		{"progressive", "make", "", "mak", "ing"},
+		{"able", "love", "", "lov", "able"},
+		{"past", "hope", "", "hope", "d"},
+		{"past", "agree", "", "agree", "d"},
+		{"comparative", "nice", "", "nice", "r"},
+		{"er", "bake", "", "bake", "r"},
+		{"progressive", "see", "", "see", "ing"},
+		{"progressive", "die", "", "dy", "ing"},
+
+		// y -> i after a consonant, but not after a vowel
+		{"past", "carry", "", "carri", "ed"},
+		{"plural", "baby", "", "babi", "es"},
+		{"third_person", "cry", "", "cri", "es"},
+		{"comparative", "happy", "", "happi", "er"},
+		{"er", "carry", "", "carri", "er"},
+		{"ness", "happy", "", "happi", "ness"},
+		{"ful", "beauty", "", "beauti", "ful"},
+		{"less", "penny", "", "penni", "less"},
+		{"able", "rely", "", "reli", "able"},
+		{"plural", "day", "", "day", "s"},
+
+		// Allomorphs chosen by the base: -es after sibilants, -ion after t
+		{"plural", "box", "", "box", "es"},
+		{"plural", "church", "", "church", "es"},
+		{"third_person", "fix", "", "fix", "es"},
+		{"tion", "protect", "", "protect", "ion"},
+	}
+	for _, tt := range tests {
+		rule, ok := affixes.Rule(tt.affix)
+		if !ok {
+			t.Fatalf("no built-in affix %q", tt.affix)
+		}
+		root := tt.root
+		if root == "" {
+			root = tt.base
+		}
+		stem, affix := rule.spell(tt.base, root)
+		if stem != tt.wantStem || affix != tt.wantAffix {
+			t.Errorf("%s + %s = %s + %s, want %s + %s", tt.base, tt.affix, stem, affix, tt.wantStem, tt.wantAffix)
+		}
+	}
+}
+
+func TestEndsWith(t *testing.T) {
+	tests := []struct {
+		word    string
+		pattern string
+		want    bool
+	}{
+		{"carry", "Cy", true},
+		{"play", "Cy", false},
+		{"stop", "CVC", true},
+		{"stoop", "CVC", false},
+		{"box", "x", true},
+		{"hope", "e", true},
+		{"see", "Ve", true},
+		{"y", "Cy", false}, // Pattern longer than the word
+	}
+	for _, tt := range tests {
+		if got := endsWith(tt.word, tt.pattern); got != tt.want {
+			t.Errorf("endsWith(%q, %q) = %v, want %v", tt.word, tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+/* Question family registry and weighted question bank generation */
+
+package morphology
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Give up on a family after this many duplicate questions in a row (it has likely run out of
+// distinct questions for this word bank)
+const maxDuplicateRun = 50
+
+// A named question family
+type Family struct {
+	Name  string
+	Build func(g *MorphGenerator) (QuestionDoc, bool) // false when the lexicon can't support the family
+}
+
+// Wraps a family that can always produce a question
+func always(build func(*MorphGenerator) QuestionDoc) func(*MorphGenerator) (QuestionDoc, bool) {
+	return func(g *MorphGenerator) (QuestionDoc, bool) { return build(g), true }
+}
+
+// Families lists every question family, in the order they are interleaved in a bank
+func Families() []Family {
+	return []Family{
+		{Name: "morpheme_classification", Build: always((*MorphGenerator).qMorphemeClassification)},
+		{Name: "infl_vs_deriv", Build: (*MorphGenerator).qInflVsDeriv},
+		{Name: "lex_category_change", Build: always((*MorphGenerator).qLexCategoryChange)},
+		{Name: "feature_encoding", Build: (*MorphGenerator).qFeatureEncoding},
+		{Name: "morpheme_counting", Build: always((*MorphGenerator).qMorphemeCounting)},
+		{Name: "well_formedness", Build: (*MorphGenerator).qWellFormedness},
+		{Name: "allomorphy", Build: (*MorphGenerator).qAllomorphy},
+		{Name: "phonological_allomorphy", Build: (*MorphGenerator).qPhonologicalAllomorphy},
+		{Name: "irregularity", Build: (*MorphGenerator).qIrregularity},
+		{Name: "irregular_class", Build: (*MorphGenerator).qIrregularClass},
+		{Name: "irregular_example", Build: (*MorphGenerator).qIrregularExample},
+		{Name: "correct_past", Build: (*MorphGenerator).qCorrectPast},
+		{Name: "correct_plural", Build: (*MorphGenerator).qCorrectPlural},
+		{Name: "nonce_plural", Build: (*MorphGenerator).qNoncePlural},
+		{Name: "nonce_past", Build: (*MorphGenerator).qNoncePast},
+		{Name: "nonce_agent", Build: (*MorphGenerator).qNonceAgent},
+		{Name: "root_meaning", Build: (*MorphGenerator).qRootMeaning},
+		{Name: "shared_root", Build: (*MorphGenerator).qSharedRoot},
+		{Name: "prefix_meaning", Build: (*MorphGenerator).qPrefixMeaning},
+		{Name: "prefix_identification", Build: (*MorphGenerator).qPrefixIdentification},
+		{Name: "prefix_selection", Build: (*MorphGenerator).qPrefixSelection},
+		{Name: "derived_category", Build: (*MorphGenerator).qDerivedCategory},
+		{Name: "homophonous_affix", Build: (*MorphGenerator).qHomophonousAffix},
+		{Name: "segmentation", Build: (*MorphGenerator).qSegmentation},
+		{Name: "segmentation_free", Build: (*MorphGenerator).qSegmentationFree},
+		{Name: "bracketing", Build: (*MorphGenerator).qBracketing},
+		{Name: "attachment_order", Build: (*MorphGenerator).qAttachmentOrder},
+		{Name: "compound_identification", Build: (*MorphGenerator).qCompoundIdentification},
+		{Name: "compound_head", Build: (*MorphGenerator).qCompoundHead},
+		{Name: "compound_roots", Build: (*MorphGenerator).qCompoundRoots},
+	}
+}
+
+// FamilyNames lists the name of every family
+func FamilyNames() []string {
+	var names []string
+	for _, f := range Families() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Options for Generate
+type GenerateOptions struct {
+	Count   int                // Total number of questions
+	Weights map[string]float64 // Relative weight per family name; families not listed default to 1
+}
+
+// Generate builds opts.Count distinct questions split across families in proportion to their
+// weights. Families that can't produce (enough distinct) questions hand their share to the rest.
+func Generate(g *MorphGenerator, opts GenerateOptions) ([]QuestionDoc, error) {
+	families := Families()
+	quotas, err := familyQuotas(families, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each question gets its own seed drawn from the generator's stream, so any single
+	// question can later be rebuilt with Regenerate.
+	seeds := rand.New(rand.NewSource(g.rng.Int63()))
+
+	var docs []QuestionDoc
+	seen := map[string]bool{}
+	duplicateRun := make([]int, len(families))
+	exhausted := make([]bool, len(families))
+
+	// Try to add one new question from family i; duplicates of earlier questions are dropped
+	try := func(i int) bool {
+		f := families[i]
+		seed := seeds.Int63()
+		g.Reseed(seed)
+		q, ok := f.Build(g)
+		if !ok {
+			exhausted[i] = true
+			return false
+		}
+		q.Family = f.Name
+		q.Seed = seed
+		g.finalize(&q)
+		key := sameQuestionKey(q)
+		if seen[key] {
+			duplicateRun[i]++
+			exhausted[i] = duplicateRun[i] >= maxDuplicateRun
+			return false
+		}
+		duplicateRun[i] = 0
+		seen[key] = true
+		docs = append(docs, q)
+		return true
+	}
+
+	// Interleave families round-robin until every quota is met
+	shortfall := 0
+	for pending := true; pending; {
+		pending = false
+		for i := range families {
+			if quotas[i] == 0 {
+				continue
+			}
+			if try(i) {
+				quotas[i]--
+			}
+			if exhausted[i] {
+				shortfall += quotas[i]
+				quotas[i] = 0
+			}
+			pending = pending || quotas[i] > 0
+		}
+	}
+
+	// Top up from families that can still produce questions
+	for shortfall > 0 {
+		pending := false
+		for i, f := range families {
+			if shortfall == 0 || exhausted[i] || weightOf(opts, f.Name) == 0 {
+				continue
+			}
+			if try(i) {
+				shortfall--
+			}
+			pending = pending || !exhausted[i]
+		}
+		if !pending {
+			return docs, fmt.Errorf("only %d of %d distinct questions could be generated", len(docs), opts.Count)
+		}
+	}
+
+	return docs, nil
+}
+
+// Sample builds n questions from a single family (used for previews)
+func Sample(g *MorphGenerator, family string, n int) ([]QuestionDoc, error) {
+	var docs []QuestionDoc
+	for i := 0; i < n; i++ {
+		q, ok, err := Regenerate(g, family, g.rng.Int63())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		docs = append(docs, q)
+	}
+	return docs, nil
+}
+
+// Regenerate rebuilds the question a family produced for seed (given the same word bank).
+// ok is false when the family can't produce a question from this lexicon.
+func Regenerate(g *MorphGenerator, family string, seed int64) (q QuestionDoc, ok bool, err error) {
+	for _, f := range Families() {
+		if f.Name != family {
+			continue
+		}
+		g.Reseed(seed)
+		q, ok = f.Build(g)
+		if !ok {
+			return q, false, nil
+		}
+		q.Family = f.Name
+		q.Seed = seed
+		g.finalize(&q)
+		return q, true, nil
+	}
+	return q, false, fmt.Errorf("unknown question family %q", family)
+}
+
+func weightOf(opts GenerateOptions, family string) float64 {
+	if w, ok := opts.Weights[family]; ok {
+		return w
+	}
+	return 1
+}
+
+// Split opts.Count across families by weight using the largest remainder method
+func familyQuotas(families []Family, opts GenerateOptions) ([]int, error) {
+	known := map[string]bool{}
+	for _, f := range families {
+		known[f.Name] = true
+	}
+	for name, w := range opts.Weights {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown question family %q", name)
+		}
+		if w < 0 {
+			return nil, fmt.Errorf("weight for %q must not be negative", name)
+		}
+	}
+	if opts.Count <= 0 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+
+	total := 0.0
+	for _, f := range families {
+		total += weightOf(opts, f.Name)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("at least one family needs a positive weight")
+	}
+
+	quotas := make([]int, len(families))
+	remainders := make([]float64, len(families))
+	assigned := 0
+	for i, f := range families {
+		exact := float64(opts.Count) * weightOf(opts, f.Name) / total
+		quotas[i] = int(math.Floor(exact))
+		remainders[i] = exact - float64(quotas[i])
+		assigned += quotas[i]
+	}
+
+	order := make([]int, len(families))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:opts.Count-assigned] {
+		quotas[i]++
+	}
+	return quotas, nil
+}
+
+// ContentID derives a stable question id from what the player sees and the expected answer,
+// so the same question always gets the same id no matter when or how it was generated.
+func ContentID(q QuestionDoc) string {
+	distractors := append([]string{}, q.Distractors...)
+	sort.Strings(distractors)
+
+	h := sha256.New()
+	for _, part := range append([]string{q.Family, q.QuestionType, q.QuestionText, q.CorrectAnswer}, distractors...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return "q" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Like ContentID, but the same for every paraphrase of a question, so a bank doesn't ask the
+// same thing twice in different words
+func sameQuestionKey(q QuestionDoc) string {
+	if q.canonical != "" {
+		q.QuestionText = q.canonical
+	}
+	return ContentID(q)
+}
+
+// Fill in the legacy fields the server reads, the content id and the shuffled choice list
+func (g *MorphGenerator) finalize(q *QuestionDoc) {
+	q.ID = ContentID(*q)
+	q.Text = q.QuestionText
+	q.Answer = q.CorrectAnswer
+	switch q.QuestionType {
+	case "TF":
+		q.Choices = []string{g.label("true"), g.label("false")}
+	case "FR":
+		q.Choices = []string{} // Typed in by the player
+	default:
+		q.Choices = append([]string{q.CorrectAnswer}, q.Distractors...)
+		// Shuffle choices but keep correct_answer stable
+		g.rng.Shuffle(len(q.Choices), func(i, j int) { q.Choices[i], q.Choices[j] = q.Choices[j], q.Choices[i] })
+	}
+}
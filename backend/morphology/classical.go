@@ -0,0 +1,245 @@
+/* Bound Latin and Greek roots: the root dictionary and splitting words built on them */
+
+package morphology
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// A bound classical root and the words built on it
+type ClassicalRoot struct {
+	Root     string   `json:"root"`
+	Forms    []string `json:"forms"`    // Spellings inside words, longest first (aqua, aque, aqu)
+	Meaning  string   `json:"meaning"`  // water
+	Origin   string   `json:"origin"`   // Latin|Greek
+	Examples []string `json:"examples"` // aquatic, aquarium
+
+	splits map[string][]string // Morphemes of the examples written with boundaries (aqu+atic)
+}
+
+// Built-in root dictionary
+//
+//go:embed data/classical.jsonl
+var classicalList []byte
+
+func parseClassicalRoots(r io.Reader) ([]ClassicalRoot, error) {
+	scanner := bufio.NewScanner(r)
+	var out []ClassicalRoot
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var root ClassicalRoot
+		if err := json.Unmarshal([]byte(line), &root); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if root.Root == "" || len(root.Forms) == 0 || root.Meaning == "" {
+			return nil, fmt.Errorf("line %d: a root needs a root, forms and a meaning", lineNo)
+		}
+		// "aqu+atic": the example's morphemes, where the root's spelling alone would mislead
+		root.splits = map[string][]string{}
+		for i, ex := range root.Examples {
+			if !strings.Contains(ex, "+") {
+				continue
+			}
+			pieces := strings.Split(ex, "+")
+			if !slices.ContainsFunc(pieces, func(p string) bool { return slices.Contains(root.Forms, p) }) {
+				return nil, fmt.Errorf("line %d: example %q splits off none of the forms of %s", lineNo, ex, root.Root)
+			}
+			word := strings.Join(pieces, "")
+			root.Examples[i] = word
+			root.splits[word] = pieces
+		}
+		out = append(out, root)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClassicalRoots lists the root dictionary
+func (l *Lexicon) ClassicalRoots() []ClassicalRoot {
+	return l.classical
+}
+
+// RootsOf returns the bound roots in a word. Bank words name theirs with "roots"; a word the
+// dictionary gives as an example has that root plus any other dictionary root spelled in it
+// (television: tele and vis).
+func (l *Lexicon) RootsOf(word string) []ClassicalRoot {
+	var out []ClassicalRoot
+	if names := l.entries[word].Roots; len(names) > 0 {
+		for _, r := range l.classical {
+			if slices.Contains(names, r.Root) {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+
+	var spans [][2]int
+	for _, r := range l.classical {
+		if slices.Contains(r.Examples, word) {
+			if start, form, ok := r.find(word); ok {
+				out = append(out, r)
+				spans = append(spans, [2]int{start, start + len(form)})
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	for _, r := range l.classical {
+		start, form, ok := r.find(word)
+		if !ok || slices.ContainsFunc(out, func(o ClassicalRoot) bool { return o.Root == r.Root }) {
+			continue
+		}
+		// A spelling that overlaps a listed root is a coincidence (vivid has viv, not vid)
+		end := start + len(form)
+		if !slices.ContainsFunc(spans, func(s [2]int) bool { return start < s[1] && s[0] < end }) {
+			out = append(out, r)
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	return out
+}
+
+// ClassicalWords lists the words known to contain bound roots: bank words that name them,
+// then the dictionary's examples
+func (l *Lexicon) ClassicalWords() []string {
+	var out []string
+	for _, w := range l.Words {
+		if len(l.entries[w].Roots) > 0 && !slices.Contains(out, w) {
+			out = append(out, w)
+		}
+	}
+	for _, r := range l.classical {
+		for _, w := range r.Examples {
+			if !slices.Contains(out, w) {
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// Where the root is spelled in word, by its first form that appears
+func (r ClassicalRoot) find(word string) (int, string, bool) {
+	for _, f := range r.Forms {
+		if i := strings.Index(word, f); i >= 0 {
+			return i, f, true
+		}
+	}
+	return 0, "", false
+}
+
+// Shown as "aqua (Latin)"
+func (r ClassicalRoot) label() string {
+	if r.Origin == "" {
+		return r.Root
+	}
+	return fmt.Sprintf("%s (%s)", r.Root, r.Origin)
+}
+
+// Whether two words have a bound root in common
+func (l *Lexicon) shareRoot(a, b string) bool {
+	for _, r := range l.RootsOf(a) {
+		if slices.ContainsFunc(l.RootsOf(b), func(o ClassicalRoot) bool { return o.Root == r.Root }) {
+			return true
+		}
+	}
+	return false
+}
+
+// A word built on bound roots, split into the roots and the pieces around them (aquatic ->
+// aqu + atic, television -> tele + vis + ion). The pieces are bound affixes; ones between two
+// roots have no position. An example written with its boundaries is split there; otherwise
+// each root is found by its spelling. ok is false for words without bound roots.
+func (g *MorphGenerator) classicalForm(word, cat string) (WordForm, bool) {
+	type span struct {
+		start int
+		form  string
+		root  ClassicalRoot
+	}
+	roots := g.lex.RootsOf(word)
+	var pieces []string
+	for _, r := range roots {
+		if p, ok := r.splits[word]; ok {
+			pieces = p
+			break
+		}
+	}
+	var spans []span
+	for _, r := range roots {
+		start, form, ok := r.find(word)
+		if pieces != nil {
+			ok = false
+			for i, at := 0, 0; i < len(pieces); at, i = at+len(pieces[i]), i+1 {
+				if slices.Contains(r.Forms, pieces[i]) {
+					start, form, ok = at, pieces[i], true
+					break
+				}
+			}
+		}
+		if !ok {
+			return WordForm{}, false
+		}
+		spans = append(spans, span{start, form, r})
+	}
+	if len(spans) == 0 {
+		return WordForm{}, false
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	// Affixes between the roots break at the example's boundaries too (terr + estr + ial)
+	var cuts []int
+	for i, at := 0, 0; i < len(pieces); i++ {
+		at += len(pieces[i])
+		cuts = append(cuts, at)
+	}
+
+	w := rootForm(word, cat)
+	w.Morphemes = nil
+	pos := 0
+	piece := func(end int, position string) {
+		for pos < end {
+			next := end
+			for _, c := range cuts {
+				if c > pos && c < next {
+					next = c
+				}
+			}
+			w.Morphemes = append(w.Morphemes, Morpheme{
+				Surface: word[pos:next], Role: "affix", Bound: true, MorphType: "derivational", Position: position, Features: map[string]string{},
+			})
+			pos = next
+		}
+	}
+	for i, s := range spans {
+		if s.start < pos {
+			return WordForm{}, false // Overlapping roots
+		}
+		position := ""
+		if i == 0 {
+			position = "prefix"
+		}
+		piece(s.start, position)
+		w.Morphemes = append(w.Morphemes, Morpheme{
+			Surface: s.form, Role: "root", Bound: true, MorphType: "bound",
+			Features: map[string]string{"root": s.root.Root, "meaning": s.root.Meaning, "origin": s.root.Origin},
+		})
+		pos = s.start + len(s.form)
+	}
+	piece(len(word), "suffix")
+	return w, true
+}
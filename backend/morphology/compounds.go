@@ -0,0 +1,71 @@
+/* Compound words: splitting a word into two free roots and finding its head */
+
+package morphology
+
+import "slices"
+
+// Compound splits a word into two free roots of at least 3 letters each (streambed -> stream
+// + bed). A root is an annotated lexicon word or one of the built-in compound roots. English
+// compounds are right-headed, so head is the second part. A word whose entry records its
+// morphemes is split the way they say instead.
+func (l *Lexicon) Compound(word string) (left, head string, ok bool) {
+	if _, isRoot := l.roots[word]; isRoot {
+		return "", "", false
+	}
+	if e := l.entries[word]; len(e.Morphemes) > 0 {
+		return "", "", false
+	}
+	for i := 3; i <= len(word)-3; i++ {
+		_, lok := l.part(word[:i])
+		_, rok := l.part(word[i:])
+		if lok && rok {
+			return word[:i], word[i:], true
+		}
+	}
+	return "", "", false
+}
+
+// What the lexicon knows about a word as a compound part: its annotated entry or its root
+// list entry
+func (l *Lexicon) part(word string) (Entry, bool) {
+	if e, ok := l.entries[word]; ok && e.Annotated() {
+		return e, true
+	}
+	e, ok := l.roots[word]
+	return e, ok
+}
+
+// Endocentric reports whether a compound's head has the compound's own category (a streambed
+// is a kind of bed; a hideaway is not a kind of away)
+func (l *Lexicon) Endocentric(word string) bool {
+	_, head, ok := l.Compound(word)
+	if !ok {
+		return false
+	}
+	e, _ := l.part(head)
+	return slices.Contains(append([]string{e.POS}, e.Also...), l.CategoryOf(word))
+}
+
+// Compounds lists the bank words that split into two roots, in bank order
+func (l *Lexicon) Compounds() []string {
+	var out []string
+	for _, w := range l.Words {
+		if _, _, ok := l.Compound(w); ok && l.CategoryOf(w) != POSOther {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// Whether a word starts or ends with a known root plus at least 3 more letters. Compounds
+// with an inflected part (caretaker, softsteps) don't split, but still can't pass for simple.
+func (l *Lexicon) partlyCompound(word string) bool {
+	for i := 3; i <= len(word)-3; i++ {
+		_, lok := l.part(word[:i])
+		_, rok := l.part(word[i:])
+		if lok || rok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+/* Explanations shown after answering: a word's morphemes and what each one is */
+
+package morphology
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explain spells out a word's morphemes, their roles and types:
+// "walkers = walk (free root) + er (derivational, verb→noun) + s (inflectional plural)". A
+// base that looks complex itself isn't called a root: "alertnesses = alertness + es (...)".
+func (g *MorphGenerator) Explain(w WordForm) string {
+	var parts []string
+	for _, m := range w.Morphemes {
+		if desc := g.describe(m); desc != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", m.Surface, desc))
+		} else {
+			parts = append(parts, m.Surface)
+		}
+	}
+	return w.Surface + " = " + strings.Join(parts, " + ")
+}
+
+// Explains several words, one after the other
+func (g *MorphGenerator) explainAll(words ...WordForm) string {
+	var out []string
+	for _, w := range words {
+		out = append(out, g.Explain(w))
+	}
+	return strings.Join(out, "; ")
+}
+
+// What a morpheme is, e.g. "free root", "derivational, adjective→noun", "inflectional plural".
+// Empty for a free base that may hide morphemes of its own (motionless, alertness).
+func (g *MorphGenerator) describe(m Morpheme) string {
+	if m.Role == "root" {
+		if !m.Bound && m.Features["meaning"] == "" && m.Features["compound"] == "" && !g.simpleRoot(m.Surface) {
+			return ""
+		}
+		kind := "free root"
+		if m.Bound {
+			kind = "bound root"
+		}
+		switch {
+		case m.Features["meaning"] != "":
+			// Latin and Greek roots: aqu (bound root aqua, Latin for "water")
+			if root := m.Features["root"]; root != "" && root != m.Surface {
+				kind += " " + root
+			}
+			return fmt.Sprintf("%s, %s for %q", kind, m.Features["origin"], m.Features["meaning"])
+		case m.Features["compound"] != "":
+			return kind + ", compound " + m.Features["compound"]
+		}
+		return kind
+	}
+
+	if m.MorphType == "inflectional" {
+		desc := "inflectional"
+		if rule, ok := g.inflectionOf(m); ok {
+			desc += " " + rule.Function
+		}
+		if class := m.Features["irregular"]; class != "" {
+			desc += ", irregular " + strings.ReplaceAll(class, "_", " ")
+		} else if m.Features["allomorph"] == "irregular" {
+			desc += ", irregular"
+		}
+		return desc
+	}
+
+	// Derivational: the category change, or the meaning when the category stays the same (un-)
+	desc := m.MorphType
+	if from, to, ok := strings.Cut(m.Features["derivation"], "->"); ok && from != to {
+		desc += ", " + longCategory(from) + "→" + longCategory(to)
+	} else if meaning := m.Features["meaning"]; meaning != "" {
+		desc += fmt.Sprintf(", %q", meaning)
+	}
+	return desc
+}
+
+// The inflectional rule a morpheme came from, matched on its features (number: plural)
+func (g *MorphGenerator) inflectionOf(m Morpheme) (AffixRule, bool) {
+	for _, r := range g.affixes.Rules() {
+		if r.Type != "inflectional" || len(r.Features) == 0 {
+			continue
+		}
+		matches := true
+		for k, v := range r.Features {
+			if m.Features[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return r, true
+		}
+	}
+	return AffixRule{}, false
+}
+
+// Undoes shortCategory
+func longCategory(category string) string {
+	if category == "adj" {
+		return POSAdjective
+	}
+	return category
+}
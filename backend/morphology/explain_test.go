@@ -0,0 +1,44 @@
+/* Tests for the morpheme breakdowns shown after answering */
+
+package morphology
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainComplexBases(t *testing.T) {
+	lex := NewLexicon([]Entry{
+		{Word: "follow", POS: POSVerb},
+		{Word: "curve", POS: POSNoun},
+		{Word: "follower", POS: POSNoun, Morphemes: []string{"follow", "-er"}},
+		{Word: "stillness", POS: POSNoun}, // Annotated, but with no breakdown
+		{Word: "alertness"},               // Guessed from its ending
+	})
+	g := NewMorphGenerator(lex, nil)
+
+	tests := []struct {
+		name string
+		word WordForm
+		want string
+	}{
+		{"simple root", g.Pluralize(g.BaseForm("curve")), "curves = curve (free root) + s (inflectional plural)"},
+		{"built on -er", g.DeriveER(g.BaseForm("follow")), "follower = follow (free root) + er (derivational, verb→noun)"},
+		{"recorded -er base", g.Pluralize(g.BaseForm("follower")), "followers = follow (free root) + er (derivational, verb→noun) + s (inflectional plural)"},
+		{"annotated -ness base", g.Pluralize(g.BaseForm("stillness")), "stillnesses = stillness + es (inflectional plural)"},
+		{"guessed -ness base", g.Pluralize(g.BaseForm("alertness")), "alertnesses = alertness + es (inflectional plural)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.Explain(tt.word)
+			if got != tt.want {
+				t.Errorf("Explain = %q, want %q", got, tt.want)
+			}
+			for _, complex := range []string{"follower (", "stillness (", "alertness ("} {
+				if strings.Contains(got, complex) {
+					t.Errorf("Explain calls a complex base a root: %q", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+/* Morphological generator: builds inflected and derived word forms with their morphemes */
+
+package morphology
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+type Morpheme struct {
+	Surface   string            `bson:"surface" json:"surface"`
+	Role      string            `bson:"role" json:"role"` // root|affix
+	Bound     bool              `bson:"bound" json:"bound"`
+	MorphType string            `bson:"morph_type" json:"morph_type"`                 // inflectional|derivational
+	Position  string            `bson:"position,omitempty" json:"position,omitempty"` // prefix|suffix (affixes only)
+	Features  map[string]string `bson:"features" json:"features"`
+}
+
+type WordForm struct {
+	Surface   string            `bson:"surface" json:"surface"`
+	Base      string            `bson:"base" json:"base"`
+	Category  string            `bson:"category" json:"category"` // noun|verb|adjective
+	Features  map[string]string `bson:"features" json:"features"`
+	Morphemes []Morpheme        `bson:"morphemes" json:"morphemes"`
+	Tree      *TreeNode         `bson:"tree,omitempty" json:"tree,omitempty"` // Order the affixes were attached in, see TreeNode
+}
+
+type MorphGenerator struct {
+	lex     *Lexicon
+	rng     *rand.Rand // Every random choice (word picks, shuffles) goes through here
+	affixes *AffixSet
+	pron    Pronunciations // Phones for words, see Pronounce
+
+	templates *TemplateCatalog // Question wording, see prompt
+	language  string           // Instruction language questions are worded in
+
+	homophones map[string][]string // Word -> look-alike affixes that can build it, see readings
+}
+
+// NewMorphGenerator creates a generator over lex (irregular forms come from the lexicon) using
+// the built-in affix rules, pronunciations and English templates. rng drives every random
+// choice, so a seeded source makes output reproducible.
+func NewMorphGenerator(lex *Lexicon, rng *rand.Rand) *MorphGenerator {
+	return &MorphGenerator{
+		lex: lex, rng: rng, affixes: DefaultAffixes(), pron: DefaultPronunciations(),
+		templates: DefaultTemplates(), language: DefaultLanguage,
+	}
+}
+
+// UseAffixes swaps in a different set of affix rules (see LoadAffixRules)
+func (g *MorphGenerator) UseAffixes(set *AffixSet) {
+	g.affixes = set
+	g.homophones = nil
+}
+
+// UseTemplates swaps in a different template catalog (see LoadTemplates) and words questions in
+// language. Questions language has no templates for stay in the default language.
+func (g *MorphGenerator) UseTemplates(c *TemplateCatalog, language string) error {
+	if !slices.Contains(c.Languages(), language) {
+		return fmt.Errorf("no templates for language %q", language)
+	}
+	g.templates, g.language = c, language
+	return nil
+}
+
+// Reseed restarts the generator's random stream
+func (g *MorphGenerator) Reseed(seed int64) {
+	g.rng.Seed(seed)
+}
+
+// BaseForm wraps a bare word as a single free root, as two roots when it is a compound
+// (streambed -> stream + bed), or as bound roots and the pieces around them (aqua + tic)
+func (g *MorphGenerator) BaseForm(word string) WordForm {
+	cat := g.lex.CategoryOf(word)
+	if w, ok := g.derivedForm(word, cat); ok {
+		return w
+	}
+	if left, head, ok := g.lex.Compound(word); ok {
+		return WordForm{
+			Surface:  word,
+			Base:     word,
+			Category: cat,
+			Features: map[string]string{"compound": "true"},
+			Morphemes: []Morpheme{
+				{Surface: left, Role: "root", Bound: false, MorphType: "free", Features: map[string]string{"compound": "modifier"}},
+				{Surface: head, Role: "root", Bound: false, MorphType: "free", Features: map[string]string{"compound": "head"}},
+			},
+			Tree: &TreeNode{Surface: word, Category: cat, Children: []*TreeNode{
+				{Surface: left, Role: "root"},
+				{Surface: head, Role: "root"},
+			}},
+		}
+	}
+	if w, ok := g.classicalForm(word, cat); ok {
+		return w
+	}
+	return rootForm(word, cat)
+}
+
+// A bank word built from the morphemes its entry records (un- + faze + -ed): the roots,
+// compounded when there are several, take the suffixes in order and then the prefixes. The
+// affixes made a new word, so they count as derivational even where they look like
+// inflections (the noun crossing, the adjective flooded).
+func (g *MorphGenerator) derivedForm(word, cat string) (WordForm, bool) {
+	e, ok := g.lex.Entry(word)
+	if !ok || len(e.Morphemes) == 0 {
+		return WordForm{}, false
+	}
+	var prefixes, roots, suffixes []string
+	for _, m := range e.Morphemes {
+		switch {
+		case strings.HasSuffix(m, "-"):
+			prefixes = append(prefixes, strings.TrimSuffix(m, "-"))
+		case strings.HasPrefix(m, "-"):
+			suffixes = append(suffixes, strings.TrimPrefix(m, "-"))
+		default:
+			roots = append(roots, m)
+		}
+	}
+
+	w := WordForm{Surface: word, Base: word, Category: cat, Features: map[string]string{}}
+	var rootNodes []*TreeNode
+	for i, r := range roots {
+		m := Morpheme{Surface: r, Role: "root", Bound: false, MorphType: "free", Features: map[string]string{}}
+		if len(roots) > 1 {
+			m.Features["compound"] = "modifier"
+			if i == len(roots)-1 {
+				m.Features["compound"] = "head"
+			}
+		}
+		w.Morphemes = append(w.Morphemes, m)
+		rootNodes = append(rootNodes, &TreeNode{Surface: r, Category: g.partCategory(r), Role: "root"})
+	}
+	tree := rootNodes[0]
+	if len(roots) > 1 {
+		head := rootNodes[len(rootNodes)-1]
+		tree = &TreeNode{Surface: strings.Join(roots, ""), Category: head.Category, Children: rootNodes}
+	}
+
+	// Suffixes attach inside out, then prefixes from the innermost one outwards
+	var affixes []Morpheme
+	attach := func(surface, position string, last bool) {
+		from := tree.Category
+		rule, known := g.affixes.spelled(position, surface, from)
+		to := ""
+		switch {
+		case last:
+			to = cat
+		case known:
+			to = rule.OutputOf(from)
+		}
+		if from == "" && known && len(rule.Input) > 0 {
+			from = rule.Input[0]
+		}
+		m := Morpheme{Surface: surface, Role: "affix", Bound: true, MorphType: "derivational", Position: position, Features: map[string]string{}}
+		if from != "" && to != "" {
+			m.Features["derivation"] = shortCategory(from) + "->" + shortCategory(to)
+		}
+		leaf := &TreeNode{Surface: surface, Role: "affix"}
+		if known && rule.Type == "derivational" {
+			leaf.Affix = rule.Name
+		}
+		if position == "prefix" {
+			affixes = append([]Morpheme{m}, affixes...)
+			tree = &TreeNode{Surface: surface + tree.Surface, Category: to, Children: []*TreeNode{leaf, tree}}
+		} else {
+			affixes = append(affixes, m)
+			tree = &TreeNode{Surface: tree.Surface + surface, Category: to, Children: []*TreeNode{tree, leaf}}
+		}
+	}
+	for i, s := range suffixes {
+		attach(s, "suffix", i == len(suffixes)-1 && len(prefixes) == 0)
+	}
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		attach(prefixes[i], "prefix", i == 0)
+	}
+
+	// Spelling in order: prefixes, roots, suffixes
+	var morphemes []Morpheme
+	for _, m := range affixes {
+		if m.Position == "prefix" {
+			morphemes = append(morphemes, m)
+		}
+	}
+	morphemes = append(morphemes, w.Morphemes...)
+	for _, m := range affixes {
+		if m.Position == "suffix" {
+			morphemes = append(morphemes, m)
+		}
+	}
+	w.Morphemes = morphemes
+	tree.Surface, tree.Category = word, cat
+	w.Tree = tree
+	return w, true
+}
+
+// The category of a root inside a derived word, when the lexicon knows it ("" otherwise)
+func (g *MorphGenerator) partCategory(root string) string {
+	if e, ok := g.lex.part(root); ok && e.POS != POSOther {
+		return e.POS
+	}
+	return ""
+}
+
+// A word that is a single free root of category cat
+func rootForm(word, cat string) WordForm {
+	return WordForm{
+		Surface:  word,
+		Base:     word,
+		Category: cat,
+		Features: map[string]string{},
+		Morphemes: []Morpheme{{
+			Surface:   word,
+			Role:      "root",
+			Bound:     false,
+			MorphType: "free",
+			Features:  map[string]string{},
+		}},
+		Tree: &TreeNode{Surface: word, Category: cat, Role: "root"},
+	}
+}
+
+// Pluralize adds the plural suffix (or irregular plural) to a noun
+func (g *MorphGenerator) Pluralize(noun WordForm) WordForm {
+	return g.Apply(noun, "plural")
+}
+
+// PastTense adds the past suffix (or irregular past) to a verb
+func (g *MorphGenerator) PastTense(verb WordForm) WordForm {
+	return g.Apply(verb, "past")
+}
+
+// Progressive adds -ing to a verb (run -> running)
+func (g *MorphGenerator) Progressive(verb WordForm) WordForm {
+	return g.Apply(verb, "progressive")
+}
+
+// ThirdPerson adds the third person singular present -s/-es to a verb (watch -> watches)
+func (g *MorphGenerator) ThirdPerson(verb WordForm) WordForm {
+	return g.Apply(verb, "third_person")
+}
+
+// Comparative adds -er (or the irregular comparative) to an adjective (big -> bigger)
+func (g *MorphGenerator) Comparative(adj WordForm) WordForm {
+	return g.Apply(adj, "comparative")
+}
+
+// Superlative adds -est (or the irregular superlative) to an adjective (big -> biggest)
+func (g *MorphGenerator) Superlative(adj WordForm) WordForm {
+	return g.Apply(adj, "superlative")
+}
+
+// DeriveER derives an agent noun from a verb (walk -> walker)
+func (g *MorphGenerator) DeriveER(verb WordForm) WordForm {
+	return g.Apply(verb, "er")
+}
+
+// DeriveNESS derives a state noun from an adjective (calm -> calmness)
+func (g *MorphGenerator) DeriveNESS(adj WordForm) WordForm {
+	return g.Apply(adj, "ness")
+}
+
+// DerivePrefix attaches a prefix to the front of a word (kind -> unkind)
+func (g *MorphGenerator) DerivePrefix(base WordForm, prefix string) WordForm {
+	return g.Apply(base, prefix)
+}
+
+// MorphemeSurfaces lists the surface form of each morpheme in w
+func (g *MorphGenerator) MorphemeSurfaces(w WordForm) []string {
+	var m []string
+	for _, mor := range w.Morphemes {
+		m = append(m, mor.Surface)
+	}
+	return m
+}
+
+func (g *MorphGenerator) pickVerb() string { return g.lex.Verbs[g.rng.Intn(len(g.lex.Verbs))] }
+func (g *MorphGenerator) pickNoun() string { return g.lex.Nouns[g.rng.Intn(len(g.lex.Nouns))] }
+func (g *MorphGenerator) pickAdj() string  { return g.lex.Adjectives[g.rng.Intn(len(g.lex.Adjectives))] }
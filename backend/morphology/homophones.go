@@ -0,0 +1,86 @@
+/* Look-alike affixes: different suffixes spelled the same (agentive vs comparative -er, plural vs 3sg -s) */
+
+package morphology
+
+import "slices"
+
+// Suffixes that share a spelling but not a function, grouped in rule order
+func (g *MorphGenerator) homophoneGroups() [][]AffixRule {
+	var groups [][]AffixRule
+	index := map[string]int{}
+	for _, r := range g.affixes.Positioned("suffix") {
+		if r.Function == "" {
+			continue
+		}
+		if i, ok := index[r.Surface]; ok {
+			groups[i] = append(groups[i], r)
+			continue
+		}
+		index[r.Surface] = len(groups)
+		groups = append(groups, []AffixRule{r})
+	}
+	return slices.DeleteFunc(groups, func(group []AffixRule) bool { return len(group) < 2 })
+}
+
+// readings lists every look-alike affix that could have built word from some lexicon entry,
+// in any part of speech the entry has ("cleaner" is both clean+er comparative and agentive)
+func (g *MorphGenerator) readings(word string) []string {
+	if g.homophones == nil {
+		g.homophones = map[string][]string{}
+		for _, group := range g.homophoneGroups() {
+			for _, r := range group {
+				for base := range g.lex.entries {
+					for _, cat := range g.lex.Categories(base) {
+						if !r.Accepts(cat, base) {
+							continue
+						}
+						form := g.BaseForm(base)
+						form.Category = cat
+						surface := g.Apply(form, r.Name).Surface
+						if !slices.Contains(g.homophones[surface], r.Name) {
+							g.homophones[surface] = append(g.homophones[surface], r.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return g.homophones[word]
+}
+
+// Whether r is the only look-alike affix that could have built word (bulks is plural bulk or
+// 3sg bulk; bankers reads one way). Words from an affix without look-alikes always qualify.
+func (g *MorphGenerator) readsOnlyAs(word string, r AffixRule) bool {
+	for _, name := range g.readings(word) {
+		if name != r.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// Bank words built with r, with exactly its default spelling, that can't be read as any
+// other look-alike affix. Words the lexicon lists in their own right (an entry, or another
+// word's irregular form) are left out too, whatever their part of speech, and so are bases
+// with morphemes of their own (followers, lookouts), so r is the only suffix to judge.
+func (g *MorphGenerator) unambiguous(r AffixRule) []WordForm {
+	var out []WordForm
+	for _, w := range g.inflectable(r) {
+		base := g.BaseForm(w)
+		if len(base.Morphemes) != 1 || !g.simpleRoot(w) {
+			continue
+		}
+		form := g.Apply(base, r.Name)
+		if form.Morphemes[len(form.Morphemes)-1].Surface != r.Surface {
+			continue // -es, -r and irregular forms don't look like the others
+		}
+		if g.lex.Listed(form.Surface) {
+			continue
+		}
+		readings := g.readings(form.Surface)
+		if len(readings) == 1 && readings[0] == r.Name && !slices.ContainsFunc(out, func(f WordForm) bool { return f.Surface == form.Surface }) {
+			out = append(out, form)
+		}
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+/* Heuristic part-of-speech inference for word bank entries that carry no annotation */
+
+package morphology
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inferred categories below this confidence keep a word out of every category-dependent
+// family (pluralize, deriveNESS, ...); it still shows up in the review report.
+const MinInferenceConfidence = 0.5
+
+// A guessed part of speech and the cues behind it
+type Inference struct {
+	Word       string   `json:"word"`
+	POS        string   `json:"pos"`
+	Number     string   `json:"number,omitempty"`
+	Confidence float64  `json:"confidence"`
+	Evidence   []string `json:"evidence"`
+	Derived    bool     `json:"derived,omitempty"` // Guessed from a derivational ending, so not a root to build on
+}
+
+// Used reports whether the inference is confident enough to place the word in a category
+func (inf Inference) Used() bool {
+	return inf.Confidence >= MinInferenceConfidence
+}
+
+// A suffix cue: words ending in suffix are probably pos
+type suffixCue struct {
+	suffix     string
+	pos        string
+	confidence float64
+}
+
+// Longer suffixes first so "-ness" wins over "-s" and "-ful" over "-l"
+var suffixCues = []suffixCue{
+	{"ness", POSNoun, 0.9},
+	{"ment", POSNoun, 0.8},
+	{"tion", POSNoun, 0.8},
+	{"ship", POSNoun, 0.8},
+	{"hood", POSNoun, 0.8},
+	{"ity", POSNoun, 0.7},
+	{"dom", POSNoun, 0.6},
+	{"ency", POSNoun, 0.8}, // urgency (fancy and bouncy are too short or don't match)
+	{"ancy", POSNoun, 0.8}, // infancy
+	{"acy", POSNoun, 0.7},  // privacy, accuracy
+	{"lcy", POSNoun, 0.6},  // normalcy
+	{"less", POSAdjective, 0.85},
+	{"able", POSAdjective, 0.8},
+	{"ible", POSAdjective, 0.8},
+	{"ful", POSAdjective, 0.85},
+	{"ous", POSAdjective, 0.85},
+	{"ive", POSAdjective, 0.7},
+	{"ish", POSAdjective, 0.75},
+	{"ic", POSAdjective, 0.7},
+	{"al", POSAdjective, 0.6},
+	{"ly", POSAdjective, 0.45}, // friendly, but just as often an adverb: too weak to use alone
+	{"ing", POSOther, 0.7},     // gerund or participle
+	{"ed", POSOther, 0.7},      // past participle used as an adjective
+}
+
+// Infer guesses a category for an unannotated word from its head (the right-hand part) when
+// it is a compound of known words, otherwise from its ending. The head goes first: the
+// ending of calmbody is the -y of body, not an adjective suffix.
+func (l *Lexicon) Infer(word string) Inference {
+	inf := Inference{Word: word, POS: POSNoun, Confidence: 0.3, Evidence: []string{"no cue: default noun"}}
+
+	lower := strings.ToLower(word)
+
+	// Compound: the head (right-hand part) decides the category
+	if left, head, ok := l.Compound(lower); ok {
+		if e, known := l.part(head); known && e.POS != POSOther {
+			return Inference{
+				Word:       word,
+				POS:        e.POS,
+				Number:     e.Number,
+				Confidence: 0.7,
+				Evidence:   []string{fmt.Sprintf("compound %s+%s, head %q is a %s", left, head, head, e.POS)},
+			}
+		}
+	}
+
+	// Plural of a known noun, or a compound ending in one: hindlegs, quietfeet
+	if singular, ok := l.pluralOf(lower); ok {
+		return Inference{
+			Word:       word,
+			POS:        POSNoun,
+			Number:     "plural",
+			Confidence: 0.7,
+			Evidence:   []string{fmt.Sprintf("plural of %q", singular)},
+		}
+	}
+
+	for _, cue := range suffixCues {
+		if len(lower) > len(cue.suffix)+2 && strings.HasSuffix(lower, cue.suffix) {
+			return Inference{
+				Word:       word,
+				POS:        cue.pos,
+				Confidence: cue.confidence,
+				Evidence:   []string{fmt.Sprintf("suffix -%s", cue.suffix)},
+				Derived:    true,
+			}
+		}
+	}
+
+	// Consonant + y: boggy, sunny, furry
+	if n := len(lower); n > 3 && lower[n-1] == 'y' && !isVowel(lower[n-2]) {
+		return Inference{Word: word, POS: POSAdjective, Confidence: 0.6, Evidence: []string{"suffix -y after a consonant"}, Derived: true}
+	}
+
+	// Plain -s (not -ss/-us/-is): probably an already-plural noun
+	if n := len(lower); n > 3 && lower[n-1] == 's' && !strings.ContainsRune("sui", rune(lower[n-2])) {
+		inf.Number = "plural"
+		inf.Confidence = 0.5
+		inf.Evidence = []string{"ends in -s: probably a plural noun"}
+	}
+
+	return inf
+}
+
+// Inferences lists every bank word whose category was guessed, in bank order
+func (l *Lexicon) Inferences() []Inference {
+	var out []Inference
+	for _, w := range l.Words {
+		if inf, ok := l.inferred[w]; ok {
+			out = append(out, inf)
+		}
+	}
+	return out
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
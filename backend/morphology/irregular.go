@@ -0,0 +1,175 @@
+/* Classes of irregular inflection: ablaut, suppletion, zero marking and irregular plurals */
+
+package morphology
+
+import (
+	"slices"
+	"strings"
+)
+
+// A kind of irregularity and the inflections it shows up in
+type IrregularClass struct {
+	Name        string   // Also keys how questions describe it (labels irregular.<name> in the template files)
+	Inflections []string // past|plural|comparative|superlative
+}
+
+// IrregularClasses lists every class an entry's "irregular" field can name
+var IrregularClasses = []IrregularClass{
+	{Name: "ablaut", Inflections: []string{"past"}},
+	{Name: "mixed", Inflections: []string{"past"}},
+	{Name: "spelling", Inflections: []string{"past"}},
+	{Name: "suppletion", Inflections: []string{"past", "plural", "comparative", "superlative"}},
+	{Name: "zero", Inflections: []string{"past", "plural"}},
+	{Name: "vowel_change", Inflections: []string{"plural"}},
+	{Name: "latinate", Inflections: []string{"plural"}},
+	{Name: "en_plural", Inflections: []string{"plural"}},
+}
+
+func irregularClass(name string) (IrregularClass, bool) {
+	for _, c := range IrregularClasses {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return IrregularClass{}, false
+}
+
+// The classes an inflection can show, in IrregularClasses order
+func classesFor(inflection string) []IrregularClass {
+	var out []IrregularClass
+	for _, c := range IrregularClasses {
+		if slices.Contains(c.Inflections, inflection) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// An irregular form in the lexicon: sing -> sang (ablaut)
+type IrregularForm struct {
+	Base       string
+	Form       string
+	Participle string // Past participle, for pasts that have one
+	Inflection string // past|plural|comparative|superlative
+	Class      string
+}
+
+// How questions show the form: "sing → sang → sung", "mouse → mice"
+func (f IrregularForm) display() string {
+	parts := []string{f.Base, f.Form}
+	if f.Participle != "" {
+		parts = append(parts, f.Participle)
+	}
+	return strings.Join(parts, " → ")
+}
+
+// The irregular forms an entry lists
+func (e Entry) irregularForms() []IrregularForm {
+	var out []IrregularForm
+	add := func(inflection, form string) {
+		if form == "" {
+			return
+		}
+		class := e.Irregular
+		if class == "" {
+			class = classifyIrregular(e.Word, form, inflection)
+		}
+		f := IrregularForm{Base: e.Word, Form: form, Inflection: inflection, Class: class}
+		if inflection == "past" {
+			f.Participle = e.Participle
+		}
+		out = append(out, f)
+	}
+	add("past", e.Past)
+	add("plural", e.Plural)
+	add("comparative", e.Comparative)
+	add("superlative", e.Superlative)
+	return out
+}
+
+// IrregularForms lists every irregular form the lexicon knows, built-in words first
+func (l *Lexicon) IrregularForms() []IrregularForm {
+	var out []IrregularForm
+	for _, w := range l.irregular {
+		out = append(out, l.entries[w].irregularForms()...)
+	}
+	return out
+}
+
+// Listed reports whether a word is a lexicon entry or another entry's irregular form
+func (l *Lexicon) Listed(word string) bool {
+	if _, ok := l.entries[word]; ok {
+		return true
+	}
+	return slices.ContainsFunc(l.IrregularForms(), func(f IrregularForm) bool {
+		return f.Form == word || f.Participle == word
+	})
+}
+
+// IrregularClassOf returns the class of a word's irregular form for an inflection
+func (l *Lexicon) IrregularClassOf(word, inflection string) (string, bool) {
+	for _, f := range l.entries[word].irregularForms() {
+		if f.Inflection == inflection {
+			return f.Class, true
+		}
+	}
+	return "", false
+}
+
+// Guess the class of an unannotated irregular form from its spelling
+func classifyIrregular(base, form, inflection string) string {
+	switch {
+	case form == base:
+		return "zero" // sheep, cut
+	case inflection == "plural" && latinatePlural(base, form):
+		return "latinate"
+	case inflection == "plural" && (form == base+"en" || form == base+"ren"):
+		return "en_plural"
+	case base[0] != form[0]:
+		return "suppletion" // go -> went, good -> better
+	case inflection == "plural":
+		return "vowel_change"
+	case consonants(base) == consonants(form):
+		return "ablaut" // sing -> sang
+	case strings.HasSuffix(form, "t") || strings.HasSuffix(form, "d"):
+		return "mixed" // keep -> kept
+	}
+	return "suppletion"
+}
+
+// cactus -> cacti, datum -> data, criterion -> criteria, crisis -> crises, larva -> larvae
+func latinatePlural(base, form string) bool {
+	for _, p := range [][2]string{{"us", "i"}, {"um", "a"}, {"on", "a"}, {"is", "es"}, {"a", "ae"}, {"ex", "ices"}, {"ix", "ices"}} {
+		if stem, ok := strings.CutSuffix(base, p[0]); ok && form == stem+p[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// The word with its vowels taken out (sing, sang -> sng)
+func consonants(word string) string {
+	return strings.Map(func(r rune) rune {
+		if slices.Contains([]rune("aeiouy"), r) {
+			return -1
+		}
+		return r
+	}, word)
+}
+
+// Ablaut and vowel-change plurals are the same process in verbs and nouns, so neither makes a
+// fair wrong answer for the other
+func confusableClasses(a, b string) bool {
+	pair := []string{a, b}
+	return slices.Contains(pair, "ablaut") && slices.Contains(pair, "vowel_change")
+}
+
+// The affix rule that uses the lexicon's irregular forms for an inflection
+func (g *MorphGenerator) irregularRule(inflection string) (AffixRule, bool) {
+	for _, r := range g.affixes.Rules() {
+		if r.Irregular == inflection {
+			return r, true
+		}
+	}
+	return AffixRule{}, false
+}
@@ -0,0 +1,417 @@
+/* Word bank loading and lexical category lookup */
+
+package morphology
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Parts of speech an entry can be annotated with. "other" words (participles, gerunds,
+// adverbs...) stay in the lexicon but are never used where a category matters.
+const (
+	POSNoun      = "noun"
+	POSVerb      = "verb"
+	POSAdjective = "adjective"
+	POSOther     = "other"
+)
+
+// A word bank entry. Annotated banks are JSON lines:
+//
+//	{"word": "swim", "pos": "verb", "past": "swam", "irregular": "ablaut", "freq": "high", "grade": 1}
+//	{"word": "tie", "pos": "verb", "prefixes": ["un", "re"]}
+//	{"word": "hope", "pos": "noun", "suffixes": ["ful", "less"]}
+//	{"word": "follower", "pos": "noun", "morphemes": ["follow", "-er"]}
+//
+// A line holding just a word is an unannotated entry (the old plain format).
+type Entry struct {
+	Word        string   `json:"word"`
+	POS         string   `json:"pos,omitempty"`         // noun|verb|adjective|other
+	Also        []string `json:"also,omitempty"`        // Other parts of speech the word can be (walk is also a noun)
+	Past        string   `json:"past,omitempty"`        // Irregular past tense
+	Plural      string   `json:"plural,omitempty"`      // Irregular plural
+	Comparative string   `json:"comparative,omitempty"` // Irregular comparative (better)
+	Superlative string   `json:"superlative,omitempty"` // Irregular superlative (best)
+	Participle  string   `json:"participle,omitempty"`  // Irregular past participle (sung), shown alongside the past
+	Irregular   string   `json:"irregular,omitempty"`   // Kind of irregularity, see IrregularClasses (guessed when missing)
+	Number      string   `json:"number,omitempty"`      // "plural" for nouns that are already plural (teeth, shallows)
+	Prefixes    []string `json:"prefixes,omitempty"`    // Prefixes the word is attested with (untie, retie)
+	Suffixes    []string `json:"suffixes,omitempty"`    // Attested derivational suffixes (hopeful, hopeless)
+	Roots       []string `json:"roots,omitempty"`       // Bound Latin or Greek roots in the word (aqua for aquatic), see ClassicalRoot
+	Morphemes   []string `json:"morphemes,omitempty"`   // How a derived word breaks down, affixes hyphenated (un-, faze, -ed)
+	Freq        string   `json:"freq,omitempty"`        // Frequency band: high|mid|low
+	Grade       int      `json:"grade,omitempty"`       // Grade level the word is appropriate from
+}
+
+// Annotated reports whether the entry carries a part of speech
+func (e Entry) Annotated() bool {
+	return e.POS != ""
+}
+
+// Built-in lexicon of common verbs and adjectives (with their irregular forms). Used to
+// categorize unannotated bank words and as a fallback when a bank lacks a category.
+//
+//go:embed data/core.jsonl
+var coreLexicon []byte
+
+// Free roots used only to split compounds, see Compound
+//
+//go:embed data/roots.jsonl
+var rootList []byte
+
+// LoadWordBank reads a word bank file, one entry per line (JSON or a bare word)
+func LoadWordBank(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := parseWordBank(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := checkHeadwords(entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Refuse plural nouns annotated as singular ones (softsteps), which questions would inflect
+// again (softstepses). Already-plural nouns must say so with "number": "plural"; bare words
+// are recognized as plurals by Infer.
+func checkHeadwords(bank []Entry) error {
+	lex := NewLexicon(bank)
+	for _, w := range lex.Words {
+		e := lex.entries[w]
+		if !e.Annotated() || e.POS != POSNoun || e.Number == "plural" {
+			continue
+		}
+		if singular, ok := lex.pluralOf(w); ok {
+			return fmt.Errorf("%q looks like the plural of %q: list the singular, or mark it \"number\": \"plural\"", w, singular)
+		}
+	}
+	return nil
+}
+
+// The noun a word is the plural of, directly (paws) or as a compound's head (hindlegs,
+// quietfeet)
+func (l *Lexicon) pluralOf(word string) (string, bool) {
+	heads := []int{0} // Where the plural noun may start: the whole word, or after a known root
+	for i := 3; i <= len(word)-3; i++ {
+		if _, ok := l.part(word[:i]); ok {
+			heads = append(heads, i)
+		}
+	}
+	for _, i := range heads {
+		for _, f := range l.IrregularForms() {
+			if f.Inflection == "plural" && f.Form != f.Base && word[i:] == f.Form {
+				return word[:i] + f.Base, true
+			}
+		}
+		for _, ending := range []string{"s", "es"} {
+			stem, ok := strings.CutSuffix(word[i:], ending)
+			if !ok || len(stem) < 3 {
+				continue
+			}
+			if e, ok := l.part(stem); ok && slices.Contains(append([]string{e.POS}, e.Also...), POSNoun) && e.Number != "plural" {
+				return word[:i] + stem, true
+			}
+		}
+	}
+	return "", false
+}
+
+func parseWordBank(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	var out []Entry
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Plain one-word-per-line format
+		if !strings.HasPrefix(line, "{") {
+			out = append(out, Entry{Word: line})
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		e.Word = strings.TrimSpace(e.Word)
+		if e.Word == "" {
+			return nil, fmt.Errorf("line %d: missing word", lineNo)
+		}
+		switch e.POS {
+		case "", POSNoun, POSVerb, POSAdjective, POSOther:
+		default:
+			return nil, fmt.Errorf("line %d: unknown pos %q", lineNo, e.POS)
+		}
+		for _, pos := range e.Also {
+			if pos != POSNoun && pos != POSVerb && pos != POSAdjective {
+				return nil, fmt.Errorf("line %d: unknown pos %q in also", lineNo, pos)
+			}
+		}
+		if _, ok := irregularClass(e.Irregular); e.Irregular != "" && !ok {
+			return nil, fmt.Errorf("line %d: unknown irregular class %q", lineNo, e.Irregular)
+		}
+		if err := checkMorphemes(e.Morphemes); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		out = append(out, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type Lexicon struct {
+	Words      []string
+	Verbs      []string
+	Nouns      []string
+	Adjectives []string
+	entries    map[string]Entry
+	inferred   map[string]Inference // Guesses for bank words with no annotation anywhere
+	attested   map[string][]string  // Affix name -> bases the word bank attests it on
+	roots      map[string]Entry     // Known free roots compounds are split into
+	irregular  []string             // Words with irregular forms, built-in ones first
+	classical  []ClassicalRoot      // Bound root dictionary
+}
+
+// NewLexicon sorts bank entries into categories. Annotations in the bank win; unannotated
+// words take their category from the built-in lexicon, or failing that from Infer. Words
+// inferred with low confidence are kept out of the category lists.
+func NewLexicon(bank []Entry) *Lexicon {
+	core, err := parseWordBank(bytes.NewReader(coreLexicon))
+	if err != nil {
+		panic("morphology: bad built-in lexicon: " + err.Error())
+	}
+
+	roots, err := parseWordBank(bytes.NewReader(rootList))
+	if err != nil {
+		panic("morphology: bad built-in root list: " + err.Error())
+	}
+
+	classical, err := parseClassicalRoots(bytes.NewReader(classicalList))
+	if err != nil {
+		panic("morphology: bad built-in root dictionary: " + err.Error())
+	}
+
+	lex := &Lexicon{entries: map[string]Entry{}, inferred: map[string]Inference{}, attested: map[string][]string{}, roots: map[string]Entry{}, classical: classical}
+	for _, e := range roots {
+		lex.roots[e.Word] = e
+	}
+	for _, e := range core {
+		lex.entries[e.Word] = e
+	}
+	for _, e := range bank {
+		if prev, ok := lex.entries[e.Word]; ok {
+			if !e.Annotated() {
+				e = prev // Keep what the built-in lexicon knows about a bare word
+			} else {
+				if len(e.Prefixes) == 0 {
+					e.Prefixes = prev.Prefixes
+				}
+				if len(e.Suffixes) == 0 {
+					e.Suffixes = prev.Suffixes
+				}
+				if len(e.Also) == 0 {
+					e.Also = prev.Also
+				}
+			}
+		}
+		lex.entries[e.Word] = e
+	}
+
+	// Irregular forms come from every entry, built-in ones included (Infer needs them)
+	for _, e := range append(core, bank...) {
+		if len(lex.entries[e.Word].irregularForms()) > 0 && !slices.Contains(lex.irregular, e.Word) {
+			lex.irregular = append(lex.irregular, e.Word)
+		}
+	}
+
+	for _, e := range bank {
+		if !lex.entries[e.Word].Annotated() {
+			lex.inferred[e.Word] = lex.Infer(e.Word)
+		}
+	}
+
+	for _, e := range bank {
+		lex.Words = append(lex.Words, e.Word)
+		lex.addToCategory(e.Word)
+	}
+
+	// Fallback if wordbank didn't contain enough known categories
+	if len(lex.Verbs) == 0 || len(lex.Adjectives) == 0 || len(lex.Nouns) == 0 {
+		verbs, adjs, nouns := lex.Verbs, lex.Adjectives, lex.Nouns
+		for _, e := range core {
+			switch {
+			case len(verbs) == 0 && e.POS == POSVerb:
+				lex.Verbs = append(lex.Verbs, e.Word)
+			case len(adjs) == 0 && e.POS == POSAdjective:
+				lex.Adjectives = append(lex.Adjectives, e.Word)
+			case len(nouns) == 0 && e.POS == POSNoun && e.Number != "plural":
+				lex.Nouns = append(lex.Nouns, e.Word)
+			}
+		}
+	}
+
+	// Attested affixes come from every entry too
+	for _, e := range append(core, bank...) {
+		e = lex.entries[e.Word]
+		for _, a := range append(slices.Clone(e.Prefixes), e.Suffixes...) {
+			if !slices.Contains(lex.attested[a], e.Word) {
+				lex.attested[a] = append(lex.attested[a], e.Word)
+			}
+		}
+	}
+
+	return lex
+}
+
+// A derived word's breakdown: prefixes, then one or more roots, then suffixes, at least two
+// morphemes in all (un-, faze, -ed; care, take, -er)
+func checkMorphemes(morphemes []string) error {
+	if len(morphemes) == 0 {
+		return nil
+	}
+	if len(morphemes) < 2 {
+		return fmt.Errorf("morphemes %q: a derived word has at least two", morphemes)
+	}
+	stage, roots := 0, 0 // 0 prefixes, 1 roots, 2 suffixes
+	for _, m := range morphemes {
+		if strings.Trim(m, "-") == "" {
+			return fmt.Errorf("morphemes %q: empty morpheme", morphemes)
+		}
+		var next int
+		switch {
+		case strings.HasPrefix(m, "-") && strings.HasSuffix(m, "-"):
+			return fmt.Errorf("morphemes %q: %q is hyphenated on both sides", morphemes, m)
+		case strings.HasSuffix(m, "-"):
+			next = 0
+		case strings.HasPrefix(m, "-"):
+			next = 2
+		default:
+			next = 1
+			roots++
+		}
+		if next < stage {
+			return fmt.Errorf("morphemes %q: prefixes go before the roots and suffixes after", morphemes)
+		}
+		stage = next
+	}
+	if roots == 0 {
+		return fmt.Errorf("morphemes %q: no root", morphemes)
+	}
+	return nil
+}
+
+// Files a word under its category list (already-plural nouns, "other" words and
+// low-confidence guesses are left out, and so are derived words, recorded or guessed from
+// their ending: they aren't roots to build new words on)
+func (l *Lexicon) addToCategory(word string) {
+	e := l.entries[word]
+	if len(e.Morphemes) > 0 {
+		return
+	}
+	if inf, ok := l.inferred[word]; ok {
+		if !inf.Used() || inf.Derived {
+			return
+		}
+		e.Number = inf.Number
+	}
+	switch l.CategoryOf(word) {
+	case POSAdjective:
+		l.Adjectives = append(l.Adjectives, word)
+	case POSVerb:
+		l.Verbs = append(l.Verbs, word)
+	case POSNoun:
+		if e.Number != "plural" {
+			l.Nouns = append(l.Nouns, word)
+		}
+	}
+}
+
+// The category list for noun, verb or adjective
+func (l *Lexicon) byCategory(cat string) []string {
+	switch cat {
+	case POSNoun:
+		return l.Nouns
+	case POSVerb:
+		return l.Verbs
+	case POSAdjective:
+		return l.Adjectives
+	}
+	return nil
+}
+
+// Whether a bank word is derived: its entry records its morphemes, or its category was
+// guessed from a derivational ending
+func (l *Lexicon) derived(word string) bool {
+	return len(l.entries[word].Morphemes) > 0 || l.inferred[word].Derived
+}
+
+// Entry returns what the lexicon knows about a word
+func (l *Lexicon) Entry(word string) (Entry, bool) {
+	e, ok := l.entries[word]
+	return e, ok
+}
+
+// CategoryOf returns noun, verb, adjective or other for a word (the best guess for
+// unannotated words)
+func (l *Lexicon) CategoryOf(word string) string {
+	if e, ok := l.entries[word]; ok && e.POS != "" {
+		return e.POS
+	}
+	if inf, ok := l.inferred[word]; ok {
+		return inf.POS
+	}
+	return POSNoun
+}
+
+// Categories lists every part of speech a word can be, its main one first
+func (l *Lexicon) Categories(word string) []string {
+	cats := []string{l.CategoryOf(word)}
+	for _, c := range l.entries[word].Also {
+		if !slices.Contains(cats, c) {
+			cats = append(cats, c)
+		}
+	}
+	return cats
+}
+
+// Attested lists the words an affix (by rule name) is attested on, in lexicon order
+func (l *Lexicon) Attested(affix string) []string {
+	return l.attested[affix]
+}
+
+// IrregularPast returns a verb's irregular past tense, if it has one
+func (l *Lexicon) IrregularPast(verb string) (string, bool) {
+	e, ok := l.entries[verb]
+	return e.Past, ok && e.Past != ""
+}
+
+// IrregularPlural returns a noun's irregular plural, if it has one
+func (l *Lexicon) IrregularPlural(noun string) (string, bool) {
+	e, ok := l.entries[noun]
+	return e.Plural, ok && e.Plural != ""
+}
+
+// IrregularComparative returns an adjective's irregular comparative and superlative, if it has them
+func (l *Lexicon) IrregularComparative(adj string) (string, string, bool) {
+	e, ok := l.entries[adj]
+	return e.Comparative, e.Superlative, ok && e.Comparative != ""
+}
@@ -0,0 +1,70 @@
+/* Tests for word bank entries that record how a derived word breaks down */
+
+package morphology
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckMorphemes(t *testing.T) {
+	tests := []struct {
+		morphemes []string
+		wantErr   string // Part of the error, "" when the breakdown is fine
+	}{
+		{nil, ""},
+		{[]string{"follow", "-er"}, ""},
+		{[]string{"un-", "faze", "-d"}, ""},
+		{[]string{"care", "take", "-r"}, ""},
+		{[]string{"follower"}, "at least two"},
+		{[]string{"un-", "-ness"}, "no root"},
+		{[]string{"-er", "follow"}, "before the roots"},
+		{[]string{"follow", "un-"}, "before the roots"},
+		{[]string{"-ness-", "kind"}, "both sides"},
+		{[]string{"kind", "-"}, "empty"},
+	}
+	for _, tt := range tests {
+		err := checkMorphemes(tt.morphemes)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("checkMorphemes(%q): unexpected error %v", tt.morphemes, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("checkMorphemes(%q) = %v, want an error containing %q", tt.morphemes, err, tt.wantErr)
+		}
+	}
+}
+
+// Derived entries are taken apart as recorded and kept out of the roots new words are built on
+func TestDerivedEntries(t *testing.T) {
+	lex := NewLexicon([]Entry{
+		{Word: "follow", POS: POSVerb},
+		{Word: "follower", POS: POSNoun, Morphemes: []string{"follow", "-er"}},
+		{Word: "unfazed", POS: POSAdjective, Morphemes: []string{"un-", "faze", "-d"}},
+		{Word: "caretaker", POS: POSNoun, Morphemes: []string{"care", "take", "-r"}},
+		{Word: "curve", POS: POSNoun},
+	})
+	if slices.Contains(lex.Nouns, "follower") || slices.Contains(lex.Adjectives, "unfazed") {
+		t.Errorf("derived words in the category lists: nouns %v, adjectives %v", lex.Nouns, lex.Adjectives)
+	}
+
+	g := NewMorphGenerator(lex, nil)
+	tests := []struct {
+		word    string
+		want    []string
+		bracket string
+	}{
+		{"follower", []string{"follow", "er"}, "[follow-er]"},
+		{"unfazed", []string{"un", "faze", "d"}, "[un-[faze-d]]"},
+		{"caretaker", []string{"care", "take", "r"}, "[[care-take]-r]"},
+	}
+	for _, tt := range tests {
+		w := g.BaseForm(tt.word)
+		if got := g.MorphemeSurfaces(w); !slices.Equal(got, tt.want) {
+			t.Errorf("BaseForm(%q) morphemes = %v, want %v", tt.word, got, tt.want)
+		}
+		if got := w.Tree.Bracket(); got != tt.bracket {
+			t.Errorf("BaseForm(%q) tree = %s, want %s", tt.word, got, tt.bracket)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/* Learner errors: over-regularized forms and misapplied spelling rules (runned, stoped, plaied) */
+
+package morphology
+
+import (
+	"strings"
+)
+
+// A wrong form a learner might write and the rule code it breaks
+type learnerError struct {
+	Form     string
+	Violated string
+}
+
+// What a spelling rule does, for naming the errors around it. surface is the affix's
+// usual allomorph.
+func (s SpellingRule) kind(surface string) string {
+	switch {
+	case s.Double:
+		return "consonant_doubling" // stop -> stopped
+	case s.Append == "i":
+		return "y_to_i" // cry -> cried
+	case s.Append == "y":
+		return "ie_to_y" // lie -> lying
+	case s.Drop > 0 || len(s.Surface) < len(surface):
+		return "e_drop" // hope -> hoping, hoped
+	}
+	return "es_insertion" // box -> boxes
+}
+
+// Whether a spelling rule that doesn't apply to the base nearly does, so learners apply it
+// anyway: too long to double (visit -> visitted) or y after a vowel (play -> plaied)
+func (s SpellingRule) nearlyMatches(base, root string) bool {
+	if endsWithAny(base, s.Except) {
+		return false
+	}
+	if endsWithAny(base, s.Endings) {
+		return s.MaxSyllables > 0 && syllables(root) > s.MaxSyllables
+	}
+	for _, e := range s.Endings {
+		if len(e) == 2 && e[0] == 'C' && endsWith(base, "V"+e[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Spelling mistakes on a regular form: the rule that applies left out (stoped, cryed, hopeed,
+// boxs) and rules that don't apply used anyway (visitted, plaied)
+func (r AffixRule) spellingErrors(base, root string) []learnerError {
+	var out []learnerError
+	matched, ok := r.spellingFor(base, root)
+	if ok {
+		out = append(out, learnerError{Form: base + r.Surface, Violated: "missing_" + r.Spelling[matched].kind(r.Surface)})
+	}
+	for i, s := range r.Spelling {
+		if (ok && i == matched) || !s.nearlyMatches(base, root) {
+			continue
+		}
+		stem, surface := s.apply(base, r.Surface)
+		out = append(out, learnerError{Form: stem + surface, Violated: "unneeded_" + s.kind(r.Surface)})
+	}
+	return out
+}
+
+// The errors learners make inflecting base with the named rule, and the correct form.
+// Irregular words get over-regularized (runned, childs, mouses), marked twice (childrens,
+// wented) or take the participle for the past (sung); regular ones get spelling mistakes.
+// Latinate plurals are left alone, since many have an accepted English plural (cactuses).
+func (g *MorphGenerator) learnerErrors(base WordForm, name string) (WordForm, []learnerError) {
+	rule, _ := g.affixes.Rule(name)
+	correct := g.Apply(base, name)
+	root := base.unprefixed()
+
+	var out []learnerError
+	seen := map[string]bool{correct.Surface: true, base.Surface: true}
+	add := func(e learnerError) {
+		if e.Form != "" && !seen[e.Form] {
+			seen[e.Form] = true
+			out = append(out, e)
+		}
+	}
+
+	irr, irregular := g.irregularForm(rule, base.Surface)
+	if !irregular {
+		for _, e := range rule.spellingErrors(base.Surface, root) {
+			add(e)
+		}
+		return correct, out
+	}
+
+	class, _ := g.lex.IrregularClassOf(base.Surface, rule.Irregular)
+	if class != "latinate" {
+		stem, surface := rule.spell(base.Surface, root)
+		add(learnerError{Form: stem + surface, Violated: "overregularization"})
+		for _, e := range rule.spellingErrors(base.Surface, root) {
+			add(learnerError{Form: e.Form, Violated: "overregularization"})
+		}
+	}
+	if class != "zero" && !strings.HasSuffix(irr, rule.Surface) {
+		stem, surface := rule.spell(irr, irr)
+		add(learnerError{Form: stem + surface, Violated: "double_marking"})
+	}
+	if rule.Irregular == "past" {
+		if e, ok := g.lex.Entry(base.Surface); ok && e.Participle != "" {
+			add(learnerError{Form: e.Participle, Violated: "participle_as_past"})
+		}
+	}
+	return correct, out
+}
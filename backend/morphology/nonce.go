@@ -0,0 +1,121 @@
+/* Pseudo-words for wug tests: phonotactically plausible English words that aren't real */
+
+package morphology
+
+import (
+	"slices"
+	"strings"
+)
+
+// Spelled pieces of an English syllable. Checked syllables (closed, short vowel) can end a
+// word; free ones (open, long vowel) only come before another syllable: blicket, toozip.
+var (
+	nonceOnsets = []string{
+		"b", "bl", "br", "ch", "d", "dr", "f", "fl", "fr", "g", "gl", "gr", "h", "j", "k", "kl", "kr",
+		"l", "m", "n", "p", "pl", "pr", "r", "s", "sh", "sk", "sl", "sm", "sn", "sp", "st", "str",
+		"t", "th", "tr", "v", "w", "z",
+	}
+	nonceShortVowels = []string{"a", "e", "i", "o", "u"}
+	nonceLongVowels  = []string{"ee", "oo", "ai", "oa"}
+	nonceCodas       = []string{
+		"b", "ck", "d", "g", "m", "n", "p", "t", "sh", "tch", "ff", "ll", "ss", "zz", "x",
+		"sk", "st", "nd", "mp", "nk", "ft", "lt",
+	}
+	nonceInnerCodas = []string{"b", "ck", "d", "g", "m", "n", "p", "t"} // Before a vowel-initial syllable (blick-et)
+)
+
+// Letter sequences a pseudo-word must not contain, so none reads as a rude word
+var nonceBlocked = []string{
+	"ass", "bitch", "cock", "crap", "cum", "cunt", "damn", "dick", "fag", "fuck", "gay", "hell",
+	"kill", "nazi", "nig", "piss", "poo", "rap", "sex", "shit", "slut", "tit", "twat", "vomit",
+}
+
+// Vowel swaps learners borrow from irregular words: man -> men for plurals, sing -> sang for
+// pasts
+var (
+	pluralVowelSwaps = map[string]string{"a": "e", "e": "i", "i": "e", "o": "ee", "u": "i"}
+	pastVowelSwaps   = map[string]string{"a": "u", "e": "o", "i": "a", "o": "e", "u": "a"}
+)
+
+// NonceWord makes up a two-syllable pseudo-word that is not in the lexicon, the compound
+// roots or the pronunciation dictionary, doesn't split into two known words and doesn't look
+// affixed. ok is false if none turned up. One syllable would be closer to wug itself, but too
+// many of those are real words the built-in dictionary doesn't list (pin, thud).
+func (g *MorphGenerator) NonceWord() (string, bool) {
+	pick := func(list []string) string { return list[g.rng.Intn(len(list))] }
+	for range 100 {
+		var b strings.Builder
+		b.WriteString(pick(nonceOnsets))
+		if g.rng.Intn(2) == 0 {
+			// Open with a long vowel (too-zip)...
+			b.WriteString(pick(nonceLongVowels))
+			b.WriteString(pick(nonceOnsets))
+		} else {
+			// ...or closed before a bare vowel (blick-et)
+			b.WriteString(pick(nonceShortVowels))
+			b.WriteString(pick(nonceInnerCodas))
+		}
+		b.WriteString(pick(nonceShortVowels))
+		b.WriteString(pick(nonceCodas))
+
+		w := b.String()
+		if !g.isRealWord(w) && !blocked(w) && !g.looksAffixed(w) {
+			return w, true
+		}
+	}
+	return "", false
+}
+
+// Whether the generator knows word as a real word (or a compound of two)
+func (g *MorphGenerator) isRealWord(word string) bool {
+	if _, ok := g.lex.part(word); ok {
+		return true
+	}
+	if _, _, ok := g.lex.Compound(word); ok {
+		return true
+	}
+	if slices.Contains(g.lex.Words, word) {
+		return true
+	}
+	_, ok := g.pron[word]
+	return ok
+}
+
+// Whether a made-up word starts like a prefix (rebok) or ends like a suffix (thaigled), which
+// would make it read as complex
+func (g *MorphGenerator) looksAffixed(word string) bool {
+	for _, r := range g.affixes.Positioned("prefix") {
+		if strings.HasPrefix(word, r.Surface) {
+			return true
+		}
+	}
+	for _, r := range g.affixes.Positioned("suffix") {
+		if len(r.Surface) > 1 && strings.HasSuffix(word, r.Surface) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether word contains one of the blocked letter sequences
+func blocked(word string) bool {
+	for _, b := range nonceBlocked {
+		if strings.Contains(word, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// Swap the vowel of a word's last syllable (wug -> wig); ok is false if it has no swap
+func swapLastVowel(word string, swaps map[string]string) (string, bool) {
+	i := strings.LastIndexAny(word, "aeiou")
+	if i < 0 || (i > 0 && strings.ContainsRune("aeiou", rune(word[i-1]))) {
+		return "", false // Only single short vowels swap
+	}
+	to, ok := swaps[word[i:i+1]]
+	if !ok {
+		return "", false
+	}
+	return word[:i] + to + word[i+1:], true
+}
@@ -0,0 +1,49 @@
+/* Attested affix/base pairs for the prefix and derivation question families */
+
+package morphology
+
+import "strings"
+
+// An affix the lexicon attests on a base word (un + tie)
+type affixPair struct {
+	Affix string
+	Base  string
+}
+
+// Every attested (affix, base) pair for the affixes at position, in rule order. Pairs whose
+// base category the affix's selectional restriction doesn't allow are skipped.
+func (g *MorphGenerator) attestedPairs(position string) []affixPair {
+	var out []affixPair
+	for _, r := range g.affixes.Positioned(position) {
+		if !r.Attested {
+			continue
+		}
+		for _, w := range g.lex.Attested(r.Name) {
+			if r.Accepts(g.lex.CategoryOf(w), w) {
+				out = append(out, affixPair{Affix: r.Name, Base: w})
+			}
+		}
+	}
+	return out
+}
+
+// Starts with one of the prefixes' spellings (uncle, rest, dish), so it would make a
+// misleading "no prefix" choice
+func (g *MorphGenerator) hasPrefixSpelling(word string) bool {
+	for _, r := range g.affixes.Positioned("prefix") {
+		if len(word) > len(r.Surface) && strings.HasPrefix(word, r.Surface) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether piece is spelled exactly like one of the prefixes
+func (s *AffixSet) isPrefixSurface(piece string) bool {
+	for _, r := range s.Positioned("prefix") {
+		if r.Surface == piece {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/* CMU-style pronunciation dictionary, used to pick the phonological allomorph of a suffix */
+
+package morphology
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Pronunciations maps lowercase words to their ARPAbet phones, stress marks removed
+// (walk -> W AO K)
+type Pronunciations map[string][]string
+
+//go:embed data/pronunciations.dict
+var builtinPronunciations []byte
+
+// DefaultPronunciations returns the built-in dictionary (the core lexicon and default word bank)
+func DefaultPronunciations() Pronunciations {
+	p, err := parsePronunciations(bytes.NewReader(builtinPronunciations))
+	if err != nil {
+		panic("morphology: bad built-in pronunciations: " + err.Error())
+	}
+	return p
+}
+
+// LoadPronunciations reads a CMU-style dictionary on top of the built-in one; its entries
+// replace built-in ones for the same word.
+func LoadPronunciations(path string) (Pronunciations, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	extra, err := parsePronunciations(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p := DefaultPronunciations()
+	for w, phones := range extra {
+		p[w] = phones
+	}
+	return p, nil
+}
+
+// Lines look like "WALK  W AO1 K". ";;;" starts a comment, and alternate pronunciations
+// ("CLOSE(2)") are skipped in favor of the first one.
+func parsePronunciations(r io.Reader) (Pronunciations, error) {
+	p := Pronunciations{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";;;") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a word followed by its phones", lineNo)
+		}
+		if strings.HasSuffix(fields[0], ")") {
+			continue
+		}
+
+		var phones []string
+		for _, ph := range fields[1:] {
+			ph = strings.TrimRightFunc(ph, unicode.IsDigit)
+			if ph == "" || strings.ToUpper(ph) != ph {
+				return nil, fmt.Errorf("line %d: bad phone %q", lineNo, ph)
+			}
+			phones = append(phones, ph)
+		}
+		p[strings.ToLower(fields[0])] = phones
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UsePronunciations swaps in a different pronunciation dictionary (see LoadPronunciations)
+func (g *MorphGenerator) UsePronunciations(p Pronunciations) {
+	g.pron = p
+}
+
+// Pronounce looks a word up, pronouncing compounds missing from the dictionary from their
+// parts (streambed -> stream + bed)
+func (g *MorphGenerator) Pronounce(word string) ([]string, bool) {
+	if phones, ok := g.pron[word]; ok {
+		return phones, true
+	}
+	if left, head, ok := g.lex.Compound(word); ok {
+		l, lok := g.pron[left]
+		h, hok := g.pron[head]
+		if lok && hok {
+			return append(append([]string{}, l...), h...), true
+		}
+	}
+	return nil, false
+}
+
+// The suffix's pronunciation after a base ending in phones, by the first phonology rule
+// that matches the final phone
+func (r AffixRule) soundAfter(phones []string) (string, bool) {
+	if len(phones) == 0 {
+		return "", false
+	}
+	last := phones[len(phones)-1]
+	for _, ph := range r.Phonology {
+		if len(ph.After) == 0 || slices.Contains(ph.After, last) {
+			return ph.IPA, true
+		}
+	}
+	return "", false
+}
+
+// PhonologicalAllomorph is how the suffix named rule sounds on word (/s/, /z/ or /ɪz/ for the
+// plural). ok is false for words without a pronunciation, irregular forms and rules with no
+// phonology.
+func (g *MorphGenerator) PhonologicalAllomorph(rule, word string) (string, bool) {
+	r, ok := g.affixes.Rule(rule)
+	if !ok || len(r.Phonology) == 0 {
+		return "", false
+	}
+	if _, irregular := g.irregularForm(r, word); irregular {
+		return "", false
+	}
+	phones, ok := g.Pronounce(word)
+	if !ok {
+		return "", false
+	}
+	return r.soundAfter(phones)
+}
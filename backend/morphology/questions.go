@@ -0,0 +1,1461 @@
+/* Question families built on top of MorphGenerator */
+
+package morphology
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Document inserted into MongoDB
+type QuestionDoc struct {
+	ID         string   `bson:"id" json:"id"` // Content-derived, see ContentID
+	Text       string   `bson:"text" json:"text"`
+	Choices    []string `bson:"choices" json:"choices"`
+	Answer     string   `bson:"answer" json:"answer"`
+	Difficulty string   `bson:"difficulty" json:"difficulty"`
+
+	Prompt `bson:",inline"` // question_text and template_id
+
+	QuestionType  string    `bson:"question_type" json:"question_type"` // TF|MC|FR (free response)
+	CorrectAnswer string    `bson:"correct_answer" json:"correct_answer"`
+	Distractors   []string  `bson:"distractors,omitempty" json:"distractors,omitempty"`
+	ViolatedRule  []string  `bson:"violated_rule,omitempty" json:"violated_rule,omitempty"`
+	BaseWord      string    `bson:"base_word" json:"base_word"`
+	MorphemesUsed []string  `bson:"morphemes_used" json:"morphemes_used"`
+	Tree          *TreeNode `bson:"tree,omitempty" json:"tree,omitempty"` // Structure of the word asked about, for drawing
+	Explanation   string    `bson:"explanation" json:"explanation"`       // Shown after answering, see Explain
+	Family        string    `bson:"family" json:"family"`
+	Seed          int64     `bson:"seed" json:"seed"` // Regenerates this exact question (same family and word bank)
+}
+
+// --- Question families ---
+
+func (g *MorphGenerator) qMorphemeClassification() QuestionDoc {
+	// Ensure >=2 morphemes via derivation then optional inflection
+	verb := g.BaseForm(g.pickVerb())
+	word := g.DeriveER(verb)
+	if g.rng.Intn(2) == 0 {
+		word = g.Pluralize(word)
+	}
+
+	// pick an affix morpheme
+	var target Morpheme
+	for i := len(word.Morphemes) - 1; i >= 0; i-- {
+		if word.Morphemes[i].Role == "affix" {
+			target = word.Morphemes[i]
+			break
+		}
+	}
+
+	// What the morpheme really is decides the answer: pick a property it has for True, one it
+	// lacks for False
+	holds := map[string]bool{
+		"bound":        target.Bound,
+		"free":         !target.Bound,
+		"root":         target.Role == "root",
+		"affix":        target.Role == "affix",
+		"derivational": target.MorphType == "derivational",
+		"inflectional": target.MorphType == "inflectional",
+	}
+	want := g.rng.Intn(2) == 0
+	var props []string
+	for _, p := range []string{"bound", "free", "root", "affix", "derivational", "inflectional"} {
+		if holds[p] == want {
+			props = append(props, p)
+		}
+	}
+	prop := props[g.rng.Intn(len(props))]
+
+	correct := g.label("true")
+	violated := ""
+	if !want {
+		correct = g.label("false")
+		violated = "flipped_morpheme_property"
+	}
+
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        g.prompt("morpheme_classification", Slots{"word": word.Surface, "morpheme": target.Surface, "property": g.label("property." + prop)}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{violated},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}
+}
+
+func (g *MorphGenerator) qInflVsDeriv() (QuestionDoc, bool) {
+	// One option: only inflectional
+	noun := g.BaseForm(g.pickNoun())
+	correctW := g.Pluralize(noun)
+
+	// Distractors: must include derivational affix (re-pick until all four surfaces differ,
+	// giving up on a bank with too few verbs)
+	v1 := g.DeriveER(g.BaseForm(g.pickVerb()))
+	v2 := g.DeriveNESS(g.BaseForm(g.pickAdj()))
+	v3 := g.Pluralize(g.DeriveER(g.BaseForm(g.pickVerb())))
+	for i := 0; !distinct(correctW.Surface, v1.Surface, v2.Surface, v3.Surface); i++ {
+		if i == maxRepicks {
+			return QuestionDoc{}, false
+		}
+		v1 = g.DeriveER(g.BaseForm(g.pickVerb()))
+		v3 = g.Pluralize(g.DeriveER(g.BaseForm(g.pickVerb())))
+	}
+
+	correctAnswer := correctW.Surface
+	distractors := []string{v1.Surface, v2.Surface, v3.Surface}
+	violated := []string{"contains_derivational_affix", "contains_derivational_affix", "contains_derivational_affix"}
+
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("infl_vs_deriv", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: correctAnswer,
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      correctW.Base,
+		MorphemesUsed: g.MorphemeSurfaces(correctW),
+		Explanation:   g.Explain(correctW),
+	}, true
+}
+
+func (g *MorphGenerator) qLexCategoryChange() QuestionDoc {
+	adj := g.BaseForm(g.pickAdj())
+	derived := g.DeriveNESS(adj)
+
+	makeFalse := g.rng.Intn(2) == 0
+	correct := g.label("true")
+	violated := ""
+	if makeFalse {
+		correct = g.label("false")
+		violated = "incorrect_category_change_claim"
+	}
+
+	// True statement for -ness is category-changing; false flips that single claim.
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        g.prompt("lex_category_change", Slots{"suffix": "-ness", "word": adj.Surface}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{violated},
+		BaseWord:      derived.Base,
+		MorphemesUsed: g.MorphemeSurfaces(derived),
+		Explanation:   g.Explain(derived),
+	}
+}
+
+// What an inflection encodes, in the generator's language: the template files label the
+// built-in rules, and a rule file's own gloss covers the rest
+func (g *MorphGenerator) gloss(r AffixRule) string {
+	if l := g.templates.label(g.language, "gloss."+r.Name); l != "" {
+		return l
+	}
+	return r.Gloss
+}
+
+func (g *MorphGenerator) qFeatureEncoding() (QuestionDoc, bool) {
+	// Ask about any inflection the rules describe (past, plural, -ing, 3sg -s, -er/-est)
+	// that some word in the lexicon can take. Irregular forms have no suffix to point at, and
+	// words a look-alike affix could also have built are skipped: "bulks" is 3sg as much as
+	// plural, "tanner" an agent as much as a comparative.
+	var rules []AffixRule
+	var glosses []string
+	inflected := map[string][]WordForm{}
+	for _, r := range g.affixes.Positioned("suffix") {
+		gloss := g.gloss(r)
+		if r.Type != "inflectional" || gloss == "" {
+			continue
+		}
+		if !slices.Contains(glosses, gloss) {
+			glosses = append(glosses, gloss)
+		}
+		for _, w := range g.inflectable(r) {
+			form := g.Apply(g.BaseForm(w), r.Name)
+			suffix := form.Morphemes[len(form.Morphemes)-1].Surface
+			if strings.HasSuffix(form.Surface, suffix) && g.readsOnlyAs(form.Surface, r) {
+				inflected[r.Name] = append(inflected[r.Name], form)
+			}
+		}
+		if len(inflected[r.Name]) > 0 {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == 0 {
+		return QuestionDoc{}, false
+	}
+	rule := rules[g.rng.Intn(len(rules))]
+	word := inflected[rule.Name][g.rng.Intn(len(inflected[rule.Name]))]
+
+	// Distractors: what the other inflections encode
+	var pool []string
+	for _, gl := range glosses {
+		if gl != g.gloss(rule) {
+			pool = append(pool, gl)
+		}
+	}
+	if len(pool) < 3 {
+		return QuestionDoc{}, false
+	}
+	var distractors []string
+	for _, i := range g.rng.Perm(len(pool))[:3] {
+		distractors = append(distractors, pool[i])
+	}
+
+	suffix := word.Morphemes[len(word.Morphemes)-1].Surface
+	violated := []string{"feature_mismatch", "feature_mismatch", "feature_mismatch"}
+
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        g.prompt("feature_encoding", Slots{"suffix": suffix, "word": word.Surface}),
+		QuestionType:  "MC",
+		CorrectAnswer: g.gloss(rule),
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+// Bank words an affix can attach to (its categories, base shape and attestation)
+func (g *MorphGenerator) inflectable(r AffixRule) []string {
+	var out []string
+	for _, cat := range r.Input {
+		var words []string
+		switch cat {
+		case POSNoun:
+			words = g.lex.Nouns
+		case POSVerb:
+			words = g.lex.Verbs
+		case POSAdjective:
+			words = g.lex.Adjectives
+		}
+		for _, w := range words {
+			if r.Accepts(cat, w) && (!r.Attested || slices.Contains(g.lex.Attested(r.Name), w)) {
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+func (g *MorphGenerator) qMorphemeCounting() QuestionDoc {
+	verb := g.BaseForm(g.pickVerb())
+	derived := g.DeriveER(verb)
+	word := g.Pluralize(derived)
+
+	// Sometimes a compound, which has two roots (streambeds: stream + bed + s)
+	if compounds := g.lex.Compounds(); len(compounds) > 0 && g.rng.Intn(3) == 0 {
+		word = g.BaseForm(compounds[g.rng.Intn(len(compounds))])
+		if e, _ := g.lex.Entry(word.Surface); word.Category == POSNoun && e.Number != "plural" {
+			word = g.Pluralize(word)
+		}
+	}
+
+	count := len(word.Morphemes)
+	correct := fmt.Sprintf("%d", count)
+	distractors := []string{fmt.Sprintf("%d", count-1), fmt.Sprintf("%d", count+1), fmt.Sprintf("%d", count+2)}
+	violated := []string{"wrong_morpheme_count", "wrong_morpheme_count", "wrong_morpheme_count"}
+
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("morpheme_counting", Slots{"word": word.Surface}),
+		QuestionType:  "MC",
+		CorrectAnswer: correct,
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}
+}
+
+func (g *MorphGenerator) qWellFormedness() (QuestionDoc, bool) {
+	// One ill-formed word: -ness attached to a verb (violates selectional restriction). Verbs
+	// that are also adjectives (close) would make a real word (closeness).
+	verb := g.BaseForm(g.pickVerb())
+	for i := 0; slices.Contains(g.lex.Categories(verb.Surface), POSAdjective) || g.lex.Listed(verb.Surface+"ness"); i++ {
+		if i == maxRepicks {
+			return QuestionDoc{}, false
+		}
+		verb = g.BaseForm(g.pickVerb())
+	}
+	ill := WordForm{Surface: verb.Surface + "ness", Base: verb.Base, Category: "noun", Features: map[string]string{}, Morphemes: []Morpheme{
+		{Surface: verb.Surface, Role: "root", Bound: false, MorphType: "free", Features: map[string]string{}},
+		{Surface: "ness", Role: "affix", Bound: true, MorphType: "derivational", Position: "suffix", Features: map[string]string{"derivation": "adj->noun"}},
+	}}
+
+	// Well-formed distractors
+	a1 := g.DeriveNESS(g.BaseForm(g.pickAdj()))
+	a2 := g.DeriveER(g.BaseForm(g.pickVerb()))
+	a3 := g.Pluralize(g.BaseForm(g.pickNoun()))
+	for i := 0; !distinct(ill.Surface, a1.Surface, a2.Surface, a3.Surface); i++ {
+		if i == maxRepicks {
+			return QuestionDoc{}, false
+		}
+		a2 = g.DeriveER(g.BaseForm(g.pickVerb()))
+		a3 = g.Pluralize(g.BaseForm(g.pickNoun()))
+	}
+
+	correct := ill.Surface
+	distractors := []string{a1.Surface, a2.Surface, a3.Surface}
+	// Each distractor is wrong because it is well-formed; the answer itself violates -ness selection
+	violated := []string{"well_formed", "well_formed", "well_formed"}
+
+	return QuestionDoc{
+		Difficulty:    "hard",
+		Prompt:        g.prompt("well_formedness", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: correct,
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      ill.Base,
+		MorphemesUsed: g.MorphemeSurfaces(ill),
+		Explanation:   g.Explain(ill),
+	}, true
+}
+
+func (g *MorphGenerator) qAllomorphy() (QuestionDoc, bool) {
+	// Ask specifically for the plural allomorph spelled "es": one noun that triggers -es,
+	// three whose plural takes the default -s. Collect candidates up front so a bank
+	// without enough of either gives up instead of spinning.
+	var esNouns []string
+	var sPlurals []string
+	seen := map[string]bool{}
+	for _, n := range g.lex.Nouns {
+		if strings.HasSuffix(n, "s") || strings.HasSuffix(n, "x") || strings.HasSuffix(n, "z") || strings.HasSuffix(n, "ch") || strings.HasSuffix(n, "sh") {
+			esNouns = append(esNouns, n)
+			continue
+		}
+		p := g.Pluralize(g.BaseForm(n)).Surface
+		if strings.HasSuffix(p, "s") && !strings.HasSuffix(p, "es") && !seen[p] {
+			seen[p] = true
+			sPlurals = append(sPlurals, p)
+		}
+	}
+	if len(esNouns) == 0 || len(sPlurals) < 3 {
+		return QuestionDoc{}, false
+	}
+
+	correctW := g.Pluralize(g.BaseForm(esNouns[g.rng.Intn(len(esNouns))]))
+
+	// Distractors: plural with -s (not -es)
+	picked := g.rng.Perm(len(sPlurals))[:3]
+	d1, d2, d3 := sPlurals[picked[0]], sPlurals[picked[1]], sPlurals[picked[2]]
+
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("allomorphy", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: correctW.Surface,
+		Distractors:   []string{d1, d2, d3},
+		ViolatedRule:  []string{"uses_default_plural_-s", "uses_default_plural_-s", "uses_default_plural_-s"},
+		BaseWord:      correctW.Base,
+		MorphemesUsed: g.MorphemeSurfaces(correctW),
+		Explanation:   g.Explain(correctW),
+	}, true
+}
+
+func (g *MorphGenerator) qPhonologicalAllomorphy() (QuestionDoc, bool) {
+	// For each suffix with phonology rules, its forms grouped by how the suffix sounds
+	// (cats /s/, dogs /z/, horses /ɪz/). Only words the dictionary lists themselves are used:
+	// made-up bank compounds pronounced from their parts read oddly as answers.
+	type option struct {
+		rule   AffixRule
+		sounds []string
+		words  map[string][]WordForm
+	}
+	var opts []option
+	for _, r := range g.affixes.Rules() {
+		if len(r.Phonology) == 0 {
+			continue
+		}
+		o := option{rule: r, words: map[string][]WordForm{}}
+		for _, cat := range r.Input {
+			for _, w := range g.lex.byCategory(cat) {
+				if _, listed := g.pron[w]; !listed {
+					continue
+				}
+				sound, ok := g.PhonologicalAllomorph(r.Name, w)
+				if !ok || !r.Accepts(cat, w) {
+					continue
+				}
+				if len(o.words[sound]) == 0 {
+					o.sounds = append(o.sounds, sound)
+				}
+				o.words[sound] = append(o.words[sound], g.Apply(g.BaseForm(w), r.Name))
+			}
+		}
+		if len(o.sounds) >= 2 {
+			opts = append(opts, o)
+		}
+	}
+	if len(opts) == 0 {
+		return QuestionDoc{}, false
+	}
+	o := opts[g.rng.Intn(len(opts))]
+	target := o.sounds[g.rng.Intn(len(o.sounds))]
+
+	// Pick the word whose suffix has the asked-about sound
+	var others []WordForm
+	for _, sound := range o.sounds {
+		if sound != target {
+			others = append(others, o.words[sound]...)
+		}
+	}
+	if g.rng.Intn(2) == 0 && len(others) >= 3 {
+		correct := o.words[target][g.rng.Intn(len(o.words[target]))]
+		var distractors, violated []string
+		for _, i := range g.rng.Perm(len(others)) {
+			if len(distractors) == 3 {
+				break
+			}
+			if slices.Contains(distractors, others[i].Surface) {
+				continue
+			}
+			distractors = append(distractors, others[i].Surface)
+			violated = append(violated, "wrong_phonological_allomorph")
+		}
+		if len(distractors) == 3 {
+			return QuestionDoc{
+				Difficulty:    "hard",
+				Prompt:        g.prompt("phonological_allomorphy.pick", Slots{"function": o.rule.Function, "sound": target}),
+				QuestionType:  "MC",
+				CorrectAnswer: correct.Surface,
+				Distractors:   distractors,
+				ViolatedRule:  violated,
+				BaseWord:      correct.Base,
+				MorphemesUsed: g.MorphemeSurfaces(correct),
+				Explanation:   g.Explain(correct),
+			}, true
+		}
+	}
+
+	// True/False: does this word's suffix have the claimed sound?
+	word := o.words[target][g.rng.Intn(len(o.words[target]))]
+	claimed := o.sounds[g.rng.Intn(len(o.sounds))]
+	correct, violated := g.label("true"), ""
+	if claimed != target {
+		correct, violated = g.label("false"), "wrong_phonological_allomorph"
+	}
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("phonological_allomorphy.claim", Slots{"function": o.rule.Function, "word": word.Surface, "sound": claimed}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{violated},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+func (g *MorphGenerator) qIrregularity() (QuestionDoc, bool) {
+	// Pick an irregular verb that exists in the bank; distractors are regular verbs
+	var irrBases, regular []string
+	for _, w := range g.lex.Verbs {
+		if _, ok := g.lex.IrregularPast(w); ok {
+			irrBases = append(irrBases, w)
+		} else if !slices.Contains(regular, w) {
+			regular = append(regular, w)
+		}
+	}
+	if len(irrBases) == 0 || len(regular) < 3 {
+		return QuestionDoc{}, false
+	}
+	correct := irrBases[g.rng.Intn(len(irrBases))]
+
+	var distractors, violated []string
+	for _, i := range g.rng.Perm(len(regular))[:3] {
+		distractors = append(distractors, regular[i])
+		violated = append(violated, "regular_past_(-ed)")
+	}
+
+	return QuestionDoc{
+		Difficulty:    "hard",
+		Prompt:        g.prompt("irregularity", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: correct,
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      correct,
+		MorphemesUsed: []string{correct},
+		Explanation:   g.Explain(g.PastTense(g.BaseForm(correct))),
+	}, true
+}
+
+func (g *MorphGenerator) qIrregularClass() (QuestionDoc, bool) {
+	// Only inflections with at least four classes to choose from (past and plural)
+	var forms []IrregularForm
+	for _, f := range g.lex.IrregularForms() {
+		if _, ok := g.irregularRule(f.Inflection); ok && len(classesFor(f.Inflection)) >= 4 {
+			forms = append(forms, f)
+		}
+	}
+	if len(forms) == 0 {
+		return QuestionDoc{}, false
+	}
+	f := forms[g.rng.Intn(len(forms))]
+	rule, _ := g.irregularRule(f.Inflection)
+	word := g.Apply(g.BaseForm(f.Base), rule.Name)
+	correct, _ := irregularClass(f.Class)
+
+	var distractors, violated []string
+	others := slices.DeleteFunc(classesFor(f.Inflection), func(c IrregularClass) bool { return c.Name == f.Class })
+	for _, i := range g.rng.Perm(len(others))[:3] {
+		distractors = append(distractors, g.label("irregular."+others[i].Name))
+		violated = append(violated, "wrong_irregular_class")
+	}
+
+	return QuestionDoc{
+		Difficulty:    "hard",
+		Prompt:        g.prompt("irregular_class", Slots{"function": rule.Function, "form": f.Form, "forms": f.display()}),
+		QuestionType:  "MC",
+		CorrectAnswer: g.label("irregular." + correct.Name),
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      f.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+func (g *MorphGenerator) qIrregularExample() (QuestionDoc, bool) {
+	// Forms grouped by class; the answer shows the asked-about class, each distractor another one
+	byClass := map[string][]IrregularForm{}
+	var classes []string
+	for _, f := range g.lex.IrregularForms() {
+		if len(byClass[f.Class]) == 0 {
+			classes = append(classes, f.Class)
+		}
+		byClass[f.Class] = append(byClass[f.Class], f)
+	}
+	if len(classes) < 4 {
+		return QuestionDoc{}, false
+	}
+	target := classes[g.rng.Intn(len(classes))]
+	correct := byClass[target][g.rng.Intn(len(byClass[target]))]
+
+	var distractors, violated []string
+	for _, i := range g.rng.Perm(len(classes)) {
+		c := classes[i]
+		if len(distractors) == 3 {
+			break
+		}
+		if c == target || confusableClasses(c, target) {
+			continue
+		}
+		f := byClass[c][g.rng.Intn(len(byClass[c]))]
+		distractors = append(distractors, f.display())
+		violated = append(violated, "wrong_irregular_class")
+	}
+	if len(distractors) < 3 {
+		return QuestionDoc{}, false
+	}
+
+	class, _ := irregularClass(target)
+	rule, ok := g.irregularRule(correct.Inflection)
+	if !ok {
+		return QuestionDoc{}, false
+	}
+	return QuestionDoc{
+		Difficulty:    "hard",
+		Prompt:        g.prompt("irregular_example", Slots{"class": g.label("irregular." + class.Name)}),
+		QuestionType:  "MC",
+		CorrectAnswer: correct.display(),
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      correct.Base,
+		MorphemesUsed: []string{correct.Base, correct.Form},
+		Explanation:   g.Explain(g.Apply(g.BaseForm(correct.Base), rule.Name)),
+	}, true
+}
+
+func (g *MorphGenerator) qCorrectPast() (QuestionDoc, bool) {
+	return g.qCorrectForm("past")
+}
+
+func (g *MorphGenerator) qCorrectPlural() (QuestionDoc, bool) {
+	return g.qCorrectForm("plural")
+}
+
+// Which is the right inflected form, against the mistakes learners make with the word
+// (ran: runned, ranned; stopped: stoped). True/False when there aren't three mistakes to offer.
+func (g *MorphGenerator) qCorrectForm(name string) (QuestionDoc, bool) {
+	rule, ok := g.affixes.Rule(name)
+	if !ok {
+		return QuestionDoc{}, false
+	}
+
+	// Every irregular word the lexicon knows and the bank words the rule takes
+	var words []string
+	for _, f := range g.lex.IrregularForms() {
+		if f.Inflection == rule.Irregular && !slices.Contains(words, f.Base) {
+			words = append(words, f.Base)
+		}
+	}
+	for _, cat := range rule.Input {
+		for _, w := range g.lex.byCategory(cat) {
+			if rule.Accepts(cat, w) && !slices.Contains(words, w) {
+				words = append(words, w)
+			}
+		}
+	}
+
+	for _, i := range g.rng.Perm(len(words)) {
+		correct, errs := g.learnerErrors(g.BaseForm(words[i]), name)
+		if len(errs) == 0 {
+			continue
+		}
+		q := QuestionDoc{
+			Difficulty:    "medium",
+			BaseWord:      words[i],
+			MorphemesUsed: g.MorphemeSurfaces(correct),
+			Explanation:   g.Explain(correct),
+		}
+
+		if len(errs) >= 3 {
+			q.Prompt = g.prompt("correct_form.pick", Slots{"function": rule.Function, "word": words[i]})
+			q.QuestionType = "MC"
+			q.CorrectAnswer = correct.Surface
+			for _, j := range g.rng.Perm(len(errs))[:3] {
+				q.Distractors = append(q.Distractors, errs[j].Form)
+				q.ViolatedRule = append(q.ViolatedRule, errs[j].Violated)
+			}
+			return q, true
+		}
+
+		shown, answer, violated := correct.Surface, g.label("true"), ""
+		if g.rng.Intn(2) == 0 {
+			e := errs[g.rng.Intn(len(errs))]
+			shown, answer, violated = e.Form, g.label("false"), e.Violated
+		}
+		q.Prompt = g.prompt("correct_form.claim", Slots{"form": shown, "function": rule.Function, "word": words[i]})
+		q.QuestionType = "TF"
+		q.CorrectAnswer = answer
+		q.ViolatedRule = []string{violated}
+		return q, true
+	}
+	return QuestionDoc{}, false
+}
+
+func (g *MorphGenerator) qNoncePlural() (QuestionDoc, bool) {
+	return g.qNonce(POSNoun, "plural",
+		func(w WordForm) Prompt {
+			return g.prompt("nonce_plural", Slots{"word": w.Surface})
+		},
+		func(w WordForm) []learnerError {
+			out := []learnerError{{Form: w.Surface, Violated: "unmarked_form"}, {Form: w.Surface + "en", Violated: "analogical_en_plural"}}
+			if swapped, ok := swapLastVowel(w.Surface, pluralVowelSwaps); ok {
+				out = append(out, learnerError{Form: swapped, Violated: "analogical_vowel_change"})
+			}
+			return out
+		})
+}
+
+func (g *MorphGenerator) qNoncePast() (QuestionDoc, bool) {
+	return g.qNonce(POSVerb, "past",
+		func(w WordForm) Prompt {
+			return g.prompt("nonce_past", Slots{"word": w.Surface, "progressive": g.Progressive(w).Surface})
+		},
+		func(w WordForm) []learnerError {
+			out := []learnerError{{Form: w.Surface, Violated: "unmarked_form"}, {Form: g.ThirdPerson(w).Surface, Violated: "wrong_inflection"}}
+			if swapped, ok := swapLastVowel(w.Surface, pastVowelSwaps); ok {
+				out = append(out, learnerError{Form: swapped, Violated: "analogical_ablaut"})
+			}
+			return out
+		})
+}
+
+func (g *MorphGenerator) qNonceAgent() (QuestionDoc, bool) {
+	return g.qNonce(POSVerb, "er",
+		func(w WordForm) Prompt {
+			return g.prompt("nonce_agent", Slots{"word": w.Surface})
+		},
+		func(w WordForm) []learnerError {
+			return []learnerError{
+				{Form: g.PastTense(w).Surface, Violated: "wrong_affix"},
+				{Form: g.Progressive(w).Surface, Violated: "wrong_affix"},
+				{Form: g.ThirdPerson(w).Surface, Violated: "wrong_affix"},
+			}
+		})
+}
+
+// A wug test: a made-up word of category cat, a frame asking for it with the named affix, and
+// the wrong answers: spelling mistakes plus whatever wrong gives for the word
+func (g *MorphGenerator) qNonce(cat, name string, frame func(WordForm) Prompt, wrong func(WordForm) []learnerError) (QuestionDoc, bool) {
+	rule, ok := g.affixes.Rule(name)
+	if !ok {
+		return QuestionDoc{}, false
+	}
+	for range 20 {
+		word, ok := g.NonceWord()
+		if !ok {
+			return QuestionDoc{}, false
+		}
+		if !rule.Accepts(cat, word) {
+			continue
+		}
+		base := rootForm(word, cat)
+		correct := g.Apply(base, name)
+		if g.isRealWord(correct.Surface) {
+			continue
+		}
+
+		// Whether a two-syllable word doubles its final consonant depends on stress, which
+		// spelling doesn't show (treestraped, treestrapped), so leave those words out
+		errs := rule.spellingErrors(word, word)
+		if slices.ContainsFunc(errs, func(e learnerError) bool { return e.Violated == "unneeded_consonant_doubling" }) {
+			continue
+		}
+		errs = append(errs, wrong(base)...)
+		var distractors, violated []string
+		for _, i := range g.rng.Perm(len(errs)) {
+			e := errs[i]
+			if len(distractors) == 3 || e.Form == correct.Surface || slices.Contains(distractors, e.Form) {
+				continue
+			}
+			distractors = append(distractors, e.Form)
+			violated = append(violated, e.Violated)
+		}
+		if len(distractors) < 3 {
+			continue
+		}
+		return QuestionDoc{
+			Difficulty:    "medium",
+			Prompt:        frame(base),
+			QuestionType:  "MC",
+			CorrectAnswer: correct.Surface,
+			Distractors:   distractors,
+			ViolatedRule:  violated,
+			BaseWord:      word,
+			MorphemesUsed: g.MorphemeSurfaces(correct),
+			Explanation:   g.Explain(correct),
+		}, true
+	}
+	return QuestionDoc{}, false
+}
+
+func (g *MorphGenerator) qRootMeaning() (QuestionDoc, bool) {
+	words := g.lex.ClassicalWords()
+	if len(words) == 0 {
+		return QuestionDoc{}, false
+	}
+	word := words[g.rng.Intn(len(words))]
+	roots := g.lex.RootsOf(word)
+	if len(roots) == 0 {
+		return QuestionDoc{}, false
+	}
+	root := roots[g.rng.Intn(len(roots))]
+
+	// Other roots with other meanings (aqua and hydr both mean water)
+	var others []ClassicalRoot
+	for _, r := range g.lex.ClassicalRoots() {
+		if r.Meaning != root.Meaning && !slices.ContainsFunc(others, func(o ClassicalRoot) bool { return o.Meaning == r.Meaning }) {
+			others = append(others, r)
+		}
+	}
+	if len(others) < 3 {
+		return QuestionDoc{}, false
+	}
+
+	form := g.BaseForm(word)
+	q := QuestionDoc{
+		Difficulty:    "medium",
+		QuestionType:  "MC",
+		BaseWord:      word,
+		MorphemesUsed: g.MorphemeSurfaces(form),
+		Explanation:   g.Explain(form),
+	}
+	picks := g.rng.Perm(len(others))[:3]
+	if g.rng.Intn(2) == 0 {
+		q.Prompt = g.prompt("root_meaning.meaning", Slots{"word": word, "origin": root.Origin, "root": root.Root})
+		q.CorrectAnswer = root.Meaning
+		for _, i := range picks {
+			q.Distractors = append(q.Distractors, others[i].Meaning)
+		}
+	} else {
+		q.Prompt = g.prompt("root_meaning.root", Slots{"word": word, "meaning": root.Meaning})
+		q.CorrectAnswer = root.label()
+		for _, i := range picks {
+			q.Distractors = append(q.Distractors, others[i].label())
+		}
+	}
+	for range picks {
+		q.ViolatedRule = append(q.ViolatedRule, "wrong_root_meaning")
+	}
+	return q, true
+}
+
+func (g *MorphGenerator) qSharedRoot() (QuestionDoc, bool) {
+	words := g.lex.ClassicalWords()
+	for _, i := range g.rng.Perm(len(words)) {
+		word := words[i]
+		var sharing, unrelated []string
+		for _, w := range words {
+			switch {
+			case w == word:
+			case g.lex.shareRoot(word, w):
+				sharing = append(sharing, w)
+			default:
+				unrelated = append(unrelated, w)
+			}
+		}
+		if len(sharing) == 0 || len(unrelated) < 3 {
+			continue
+		}
+		correct := sharing[g.rng.Intn(len(sharing))]
+
+		// Words whose root means the same thing make the best distractors (aquatic, hydrant)
+		meanings := map[string]bool{}
+		for _, r := range g.lex.RootsOf(word) {
+			meanings[r.Meaning] = true
+		}
+		sameMeaning := func(w string) bool {
+			return slices.ContainsFunc(g.lex.RootsOf(w), func(r ClassicalRoot) bool { return meanings[r.Meaning] })
+		}
+		g.rng.Shuffle(len(unrelated), func(a, b int) { unrelated[a], unrelated[b] = unrelated[b], unrelated[a] })
+		slices.SortStableFunc(unrelated, func(a, b string) int {
+			if sameMeaning(a) == sameMeaning(b) {
+				return 0
+			}
+			if sameMeaning(a) {
+				return -1
+			}
+			return 1
+		})
+
+		var distractors, violated []string
+		for _, w := range unrelated[:3] {
+			distractors = append(distractors, w)
+			if sameMeaning(w) {
+				violated = append(violated, "same_meaning_different_root")
+			} else {
+				violated = append(violated, "different_root")
+			}
+		}
+		return QuestionDoc{
+			Difficulty:    "medium",
+			Prompt:        g.prompt("shared_root", Slots{"word": word}),
+			QuestionType:  "MC",
+			CorrectAnswer: correct,
+			Distractors:   distractors,
+			ViolatedRule:  violated,
+			BaseWord:      word,
+			MorphemesUsed: g.MorphemeSurfaces(g.BaseForm(word)),
+			Explanation:   g.explainAll(g.BaseForm(word), g.BaseForm(correct)),
+		}, true
+	}
+	return QuestionDoc{}, false
+}
+
+func (g *MorphGenerator) qPrefixMeaning() (QuestionDoc, bool) {
+	pairs := g.attestedPairs("prefix")
+	if len(pairs) == 0 {
+		return QuestionDoc{}, false
+	}
+	pair := pairs[g.rng.Intn(len(pairs))]
+	rule, _ := g.affixes.Rule(pair.Affix)
+	word := g.DerivePrefix(g.BaseForm(pair.Base), pair.Affix)
+	meaning, ok := rule.Meanings[word.Category]
+	if !ok {
+		return QuestionDoc{}, false
+	}
+	prefixes := g.affixes.Positioned("prefix")
+
+	var prompt Prompt
+	var correct string
+	var pool []string
+	if g.rng.Intn(2) == 0 {
+		// Meaning of the prefix in a word; distractors are meanings this prefix never has
+		prompt = g.prompt("prefix_meaning.meaning", Slots{"word": word.Surface, "prefix": rule.Surface})
+		correct = meaning
+		for _, r := range prefixes {
+			for _, cat := range []string{POSAdjective, POSVerb, POSNoun} {
+				m, ok := r.Meanings[cat]
+				if ok && !rule.means(m) && !slices.Contains(pool, m) {
+					pool = append(pool, m)
+				}
+			}
+		}
+	} else {
+		// Prefix with a meaning; distractors are prefixes that never mean it
+		prompt = g.prompt("prefix_meaning.prefix", Slots{"meaning": meaning})
+		correct = rule.Surface + "-"
+		for _, r := range prefixes {
+			if !r.means(meaning) && !slices.Contains(pool, r.Surface+"-") {
+				pool = append(pool, r.Surface+"-")
+			}
+		}
+	}
+	if len(pool) < 3 {
+		return QuestionDoc{}, false
+	}
+	picked := g.rng.Perm(len(pool))[:3]
+
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        prompt,
+		QuestionType:  "MC",
+		CorrectAnswer: correct,
+		Distractors:   []string{pool[picked[0]], pool[picked[1]], pool[picked[2]]},
+		ViolatedRule:  []string{"wrong_prefix_meaning", "wrong_prefix_meaning", "wrong_prefix_meaning"},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+func (g *MorphGenerator) qPrefixIdentification() (QuestionDoc, bool) {
+	pairs := g.attestedPairs("prefix")
+	if len(pairs) == 0 {
+		return QuestionDoc{}, false
+	}
+	pair := pairs[g.rng.Intn(len(pairs))]
+	correctW := g.DerivePrefix(g.BaseForm(pair.Base), pair.Affix)
+
+	// Distractors: two suffixed words and a bare word, none spelled like a prefixed word
+	verbs := slices.DeleteFunc(slices.Clone(g.lex.Verbs), g.hasPrefixSpelling)
+	adjs := slices.DeleteFunc(slices.Clone(g.lex.Adjectives), g.hasPrefixSpelling)
+	nouns := slices.DeleteFunc(slices.Clone(g.lex.Nouns), g.hasPrefixSpelling)
+	if len(verbs) == 0 || len(adjs) == 0 || len(nouns) == 0 {
+		return QuestionDoc{}, false
+	}
+	d1 := g.DeriveER(g.BaseForm(verbs[g.rng.Intn(len(verbs))]))
+	d2 := g.DeriveNESS(g.BaseForm(adjs[g.rng.Intn(len(adjs))]))
+	d3 := g.BaseForm(nouns[g.rng.Intn(len(nouns))])
+	if !distinct(correctW.Surface, d1.Surface, d2.Surface, d3.Surface) {
+		return QuestionDoc{}, false
+	}
+
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        g.prompt("prefix_identification", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: correctW.Surface,
+		Distractors:   []string{d1.Surface, d2.Surface, d3.Surface},
+		ViolatedRule:  []string{"suffix_not_prefix", "suffix_not_prefix", "no_prefix"},
+		BaseWord:      correctW.Base,
+		MorphemesUsed: g.MorphemeSurfaces(correctW),
+		Explanation:   g.Explain(correctW),
+	}, true
+}
+
+func (g *MorphGenerator) qPrefixSelection() (QuestionDoc, bool) {
+	// One ill-formed word: a prefix that doesn't take adjectives (re-, pre-, mis-) on an adjective
+	var verbOnly []string
+	for _, r := range g.affixes.Positioned("prefix") {
+		if !r.Attaches(POSAdjective) {
+			verbOnly = append(verbOnly, r.Name)
+		}
+	}
+	if len(verbOnly) == 0 {
+		return QuestionDoc{}, false
+	}
+	ill := g.DerivePrefix(g.BaseForm(g.pickAdj()), verbOnly[g.rng.Intn(len(verbOnly))])
+	if _, known := g.lex.Entry(ill.Surface); known {
+		return QuestionDoc{}, false // Happens to spell a real word
+	}
+
+	// Well-formed distractors: attested prefixed words
+	pairs := g.attestedPairs("prefix")
+	if len(pairs) < 3 {
+		return QuestionDoc{}, false
+	}
+	var distractors []string
+	for _, i := range g.rng.Perm(len(pairs))[:3] {
+		distractors = append(distractors, g.DerivePrefix(g.BaseForm(pairs[i].Base), pairs[i].Affix).Surface)
+	}
+	if !distinct(append(distractors, ill.Surface)...) {
+		return QuestionDoc{}, false
+	}
+
+	return QuestionDoc{
+		Difficulty:    "hard",
+		Prompt:        g.prompt("prefix_selection", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: ill.Surface,
+		Distractors:   distractors,
+		ViolatedRule:  []string{"well_formed", "well_formed", "well_formed"},
+		BaseWord:      ill.Base,
+		MorphemesUsed: g.MorphemeSurfaces(ill),
+		Explanation:   g.Explain(ill),
+	}, true
+}
+
+func (g *MorphGenerator) qDerivedCategory() (QuestionDoc, bool) {
+	// Any attested derivational suffix from the rule files (-ful, -able, -tion...)
+	pairs := g.attestedPairs("suffix")
+	if len(pairs) == 0 {
+		return QuestionDoc{}, false
+	}
+	pair := pairs[g.rng.Intn(len(pairs))]
+	base := g.BaseForm(pair.Base)
+	word := g.Apply(base, pair.Affix)
+
+	claimed := word.Category
+	correct := g.label("true")
+	violated := ""
+	if g.rng.Intn(2) == 0 {
+		var others []string
+		for _, c := range []string{POSNoun, POSVerb, POSAdjective} {
+			if c != word.Category {
+				others = append(others, c)
+			}
+		}
+		claimed = others[g.rng.Intn(len(others))]
+		correct = g.label("false")
+		violated = "wrong_output_category"
+	}
+
+	suffix := word.Morphemes[len(word.Morphemes)-1].Surface // The allomorph actually used (-ion in "protection")
+	return QuestionDoc{
+		Difficulty: "medium",
+		Prompt: g.prompt("derived_category", Slots{
+			"suffix": suffix, "category": base.Category, "base": base.Surface, "article": article(claimed), "claimed": claimed, "word": word.Surface,
+		}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{violated},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+// "a" or "an" for a category name
+func article(word string) string {
+	if word != "" && isVowel(word[0]) {
+		return "an"
+	}
+	return "a"
+}
+
+func (g *MorphGenerator) qHomophonousAffix() (QuestionDoc, bool) {
+	// Pick a group of look-alike suffixes where at least two functions have unambiguous words
+	type option struct {
+		rule  AffixRule
+		words []WordForm
+	}
+	var groups [][]option
+	for _, group := range g.homophoneGroups() {
+		var opts []option
+		for _, r := range group {
+			if words := g.unambiguous(r); len(words) > 0 {
+				opts = append(opts, option{r, words})
+			}
+		}
+		if len(opts) >= 2 {
+			groups = append(groups, opts)
+		}
+	}
+	if len(groups) == 0 {
+		return QuestionDoc{}, false
+	}
+	opts := groups[g.rng.Intn(len(groups))]
+	target := opts[g.rng.Intn(len(opts))]
+	suffix := "-" + target.rule.Surface
+
+	// Pick the word whose suffix has the asked-about function
+	if g.rng.Intn(2) == 0 {
+		var pool, poolRules []string
+		for _, o := range opts {
+			if o.rule.Name == target.rule.Name {
+				continue
+			}
+			for _, w := range o.words {
+				pool = append(pool, w.Surface)
+				poolRules = append(poolRules, strings.ReplaceAll(o.rule.Function+"_not_"+target.rule.Function, " ", "_"))
+			}
+		}
+		if len(pool) >= 3 {
+			correct := target.words[g.rng.Intn(len(target.words))]
+			var distractors, violated []string
+			for _, i := range g.rng.Perm(len(pool))[:3] {
+				distractors = append(distractors, pool[i])
+				violated = append(violated, poolRules[i])
+			}
+			return QuestionDoc{
+				Difficulty:    "medium",
+				Prompt:        g.prompt("homophonous_affix.pick", Slots{"suffix": suffix, "function": target.rule.Function}),
+				QuestionType:  "MC",
+				CorrectAnswer: correct.Surface,
+				Distractors:   distractors,
+				ViolatedRule:  violated,
+				BaseWord:      correct.Base,
+				MorphemesUsed: g.MorphemeSurfaces(correct),
+				Explanation:   g.Explain(correct),
+			}, true
+		}
+	}
+
+	// True/False: does this word's suffix have the claimed function?
+	claimed := opts[g.rng.Intn(len(opts))].rule
+	word := target.words[g.rng.Intn(len(target.words))]
+	correct := g.label("true")
+	violated := ""
+	if claimed.Name != target.rule.Name {
+		correct = g.label("false")
+		violated = "wrong_affix_function"
+	}
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        g.prompt("homophonous_affix.claim", Slots{"word": word.Surface, "suffix": suffix, "function": claimed.Function}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{violated},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+func (g *MorphGenerator) qSegmentation() (QuestionDoc, bool) {
+	word, segs, ok := g.segmentable()
+	if !ok {
+		return QuestionDoc{}, false
+	}
+
+	// Take wrong segmentations from each kind in turn so the choices don't all err the same way
+	byKind := g.misSegmentations(word.Surface, boundariesOf(segs))
+	kinds := slices.Sorted(maps.Keys(byKind))
+	g.rng.Shuffle(len(kinds), func(i, j int) { kinds[i], kinds[j] = kinds[j], kinds[i] })
+	var distractors, violated []string
+	for len(distractors) < 3 {
+		added := false
+		for _, kind := range kinds {
+			pool := byKind[kind]
+			if len(distractors) == 3 || len(pool) == 0 {
+				continue
+			}
+			i := g.rng.Intn(len(pool))
+			distractors = append(distractors, FormatSegmentation(pool[i].Segments))
+			violated = append(violated, pool[i].Violated)
+			byKind[kind] = slices.Delete(pool, i, i+1)
+			added = true
+		}
+		if !added {
+			return QuestionDoc{}, false
+		}
+	}
+
+	difficulty := "easy"
+	if len(segs) > 2 {
+		difficulty = "medium"
+	}
+	return QuestionDoc{
+		Difficulty:    difficulty,
+		Prompt:        g.prompt("segmentation", Slots{"word": word.Surface}),
+		QuestionType:  "MC",
+		CorrectAnswer: FormatSegmentation(segs),
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      word.Base,
+		MorphemesUsed: segs,
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+// Free-response segmentation, graded server-side by boundary position (see GradeSegmentation)
+func (g *MorphGenerator) qSegmentationFree() (QuestionDoc, bool) {
+	word, segs, ok := g.segmentable()
+	if !ok {
+		return QuestionDoc{}, false
+	}
+	difficulty := "medium"
+	if len(segs) > 2 {
+		difficulty = "hard"
+	}
+	return QuestionDoc{
+		Difficulty:    difficulty,
+		Prompt:        g.prompt("segmentation_free", Slots{"word": word.Surface}),
+		QuestionType:  "FR",
+		CorrectAnswer: FormatSegmentation(segs),
+		BaseWord:      word.Base,
+		MorphemesUsed: segs,
+		Explanation:   g.Explain(word),
+	}, true
+}
+
+func (g *MorphGenerator) qBracketing() (QuestionDoc, bool) {
+	word, alts, ok := g.bracketable()
+	if !ok {
+		return QuestionDoc{}, false
+	}
+
+	// Wrong attachment orders, plus the flat structure (every affix on the root at once)
+	var distractors, violated []string
+	for _, i := range g.rng.Perm(len(alts)) {
+		if len(distractors) == 2 {
+			break
+		}
+		distractors = append(distractors, alts[i].Tree.Bracket())
+		violated = append(violated, alts[i].Violated)
+	}
+	distractors = append(distractors, g.flatBracketing(word))
+	violated = append(violated, "flat_structure")
+
+	if len(distractors) == 3 {
+		return QuestionDoc{
+			Difficulty:    "hard",
+			Prompt:        g.prompt("bracketing.pick", Slots{"word": word.Surface}),
+			QuestionType:  "MC",
+			CorrectAnswer: word.Tree.Bracket(),
+			Distractors:   distractors,
+			ViolatedRule:  violated,
+			BaseWord:      word.Base,
+			MorphemesUsed: g.MorphemeSurfaces(word),
+			Explanation:   g.Explain(word),
+			Tree:          word.Tree,
+		}, true
+	}
+
+	// Too few bracketings for four choices: ask about one of them
+	claimed, correct, rule := word.Tree.Bracket(), g.label("true"), ""
+	if g.rng.Intn(2) == 0 {
+		i := g.rng.Intn(len(distractors))
+		claimed, correct, rule = distractors[i], g.label("false"), violated[i]
+	}
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("bracketing.claim", Slots{"bracketing": claimed, "word": word.Surface}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{rule},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+		Tree:          word.Tree,
+	}, true
+}
+
+func (g *MorphGenerator) qAttachmentOrder() (QuestionDoc, bool) {
+	word, _, ok := g.bracketable()
+	if !ok {
+		return QuestionDoc{}, false
+	}
+	_, order := attachments(word.Tree)
+	var labels []string
+	for _, a := range order {
+		labels = append(labels, g.affixLabel(a))
+	}
+
+	// Which of three or more affixes came first
+	if len(labels) >= 3 && distinct(labels...) {
+		distractors := []string{g.label("same_time")}
+		violated := []string{"flat_structure"}
+		for _, i := range g.rng.Perm(len(labels) - 1)[:2] {
+			distractors = append(distractors, labels[i+1])
+			violated = append(violated, "attached_later")
+		}
+		return QuestionDoc{
+			Difficulty:    "hard",
+			Prompt:        g.prompt("attachment_order.pick", Slots{"word": word.Surface}),
+			QuestionType:  "MC",
+			CorrectAnswer: labels[0],
+			Distractors:   distractors,
+			ViolatedRule:  violated,
+			BaseWord:      word.Base,
+			MorphemesUsed: g.MorphemeSurfaces(word),
+			Explanation:   g.Explain(word),
+			Tree:          word.Tree,
+		}, true
+	}
+
+	// True/False on a prefix and a suffix (their relative order is what the structure decides)
+	var pi, si []int
+	for i, a := range order {
+		if g.isPrefix(a) {
+			pi = append(pi, i)
+		} else {
+			si = append(si, i)
+		}
+	}
+	a, b := pi[g.rng.Intn(len(pi))], si[g.rng.Intn(len(si))]
+	if g.rng.Intn(2) == 0 {
+		a, b = b, a
+	}
+	correct, violated := g.label("true"), ""
+	if a > b {
+		correct, violated = g.label("false"), "wrong_attachment_order"
+	}
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("attachment_order.claim", Slots{"word": word.Surface, "first": labels[a], "second": labels[b]}),
+		QuestionType:  "TF",
+		CorrectAnswer: correct,
+		ViolatedRule:  []string{violated},
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+		Tree:          word.Tree,
+	}, true
+}
+
+func (g *MorphGenerator) qCompoundIdentification() (QuestionDoc, bool) {
+	compounds := g.lex.Compounds()
+	if len(compounds) == 0 {
+		return QuestionDoc{}, false
+	}
+	correct := compounds[g.rng.Intn(len(compounds))]
+
+	// Long simple words from the bank, and derived words: both have one root
+	var simple []string
+	for _, w := range g.lex.Words {
+		if !g.lex.partlyCompound(w) && len(w) >= 5 && g.lex.CategoryOf(w) != POSOther {
+			simple = append(simple, w)
+		}
+	}
+	choices := []string{correct}
+	var distractors, violated []string
+	for range 30 {
+		if len(distractors) == 3 {
+			break
+		}
+		var w WordForm
+		rule := "derived_not_compound"
+		switch g.rng.Intn(3) {
+		case 0:
+			if len(simple) == 0 {
+				continue
+			}
+			w = g.BaseForm(simple[g.rng.Intn(len(simple))])
+			rule = "simple_not_compound"
+		case 1:
+			w = g.DeriveNESS(g.BaseForm(g.pickAdj()))
+		case 2:
+			w = g.DeriveER(g.BaseForm(g.pickVerb()))
+		}
+		if g.roots(w) != 1 || g.lex.partlyCompound(w.Base) || slices.Contains(choices, w.Surface) {
+			continue
+		}
+		choices = append(choices, w.Surface)
+		distractors = append(distractors, w.Surface)
+		violated = append(violated, rule)
+	}
+	if len(distractors) < 3 {
+		return QuestionDoc{}, false
+	}
+
+	left, head, _ := g.lex.Compound(correct)
+	return QuestionDoc{
+		Difficulty:    "easy",
+		Prompt:        g.prompt("compound_identification", nil),
+		QuestionType:  "MC",
+		CorrectAnswer: correct,
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      correct,
+		MorphemesUsed: []string{left, head},
+		Explanation:   g.Explain(g.BaseForm(correct)),
+	}, true
+}
+
+func (g *MorphGenerator) qCompoundHead() (QuestionDoc, bool) {
+	// Only compounds whose head shares their category: a hideaway has no head to find
+	var pool []string
+	for _, w := range g.lex.Compounds() {
+		if g.lex.Endocentric(w) {
+			pool = append(pool, w)
+		}
+	}
+	if len(pool) == 0 {
+		return QuestionDoc{}, false
+	}
+	word := pool[g.rng.Intn(len(pool))]
+	left, head, _ := g.lex.Compound(word)
+	form := g.BaseForm(word)
+
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("compound_head", Slots{"word": word}),
+		QuestionType:  "MC",
+		CorrectAnswer: head,
+		Distractors:   []string{left, word, g.label("no_head")},
+		ViolatedRule:  []string{"modifier_not_head", "whole_word_not_head", "no_head"},
+		BaseWord:      word,
+		MorphemesUsed: []string{left, head},
+		Tree:          form.Tree,
+		Explanation:   g.Explain(form),
+	}, true
+}
+
+func (g *MorphGenerator) qCompoundRoots() (QuestionDoc, bool) {
+	// Half compounds (maybe with a suffix), half words with one root and several affixes
+	var word WordForm
+	if compounds := g.lex.Compounds(); len(compounds) > 0 && g.rng.Intn(2) == 0 {
+		word = g.BaseForm(compounds[g.rng.Intn(len(compounds))])
+		if g.rng.Intn(2) == 0 {
+			word = g.inflectOnly(word)
+		}
+	} else {
+		w, _, ok := g.segmentable()
+		if !ok {
+			return QuestionDoc{}, false
+		}
+		word = w
+	}
+
+	count := g.roots(word)
+	affixes := len(word.Morphemes) - count
+	var distractors, violated []string
+	for n := 1; n <= 4; n++ {
+		switch {
+		case n == count:
+			continue
+		case n < count:
+			violated = append(violated, "missed_root")
+		case n <= count+affixes:
+			violated = append(violated, "counted_affix_as_root")
+		default:
+			violated = append(violated, "wrong_root_count")
+		}
+		distractors = append(distractors, fmt.Sprintf("%d", n))
+	}
+	if len(distractors) != 3 {
+		return QuestionDoc{}, false
+	}
+
+	return QuestionDoc{
+		Difficulty:    "medium",
+		Prompt:        g.prompt("compound_roots", Slots{"word": word.Surface}),
+		QuestionType:  "MC",
+		CorrectAnswer: fmt.Sprintf("%d", count),
+		Distractors:   distractors,
+		ViolatedRule:  violated,
+		BaseWord:      word.Base,
+		MorphemesUsed: g.MorphemeSurfaces(word),
+		Explanation:   g.Explain(word),
+		Tree:          word.Tree,
+	}, true
+}
+
+// Number of root morphemes in a word (two for a compound)
+func (g *MorphGenerator) roots(w WordForm) int {
+	n := 0
+	for _, m := range w.Morphemes {
+		if m.Role == "root" {
+			n++
+		}
+	}
+	return n
+}
+
+// How many times a family re-picks words to make its choices distinct before giving up
+const maxRepicks = 50
+
+// Reports whether no two strings are equal
+func distinct(strs ...string) bool {
+	seen := map[string]bool{}
+	for _, s := range strs {
+		if seen[s] {
+			return false
+		}
+		seen[s] = true
+	}
+	return true
+}
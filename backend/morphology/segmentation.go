@@ -0,0 +1,282 @@
+/* Morpheme segmentation: boundary positions, plausible mis-segmentations and answer grading */
+
+package morphology
+
+import (
+	"slices"
+	"strings"
+)
+
+// Segments splits a word's surface into its morphemes (walkers -> walk, er, s). ok is false
+// when the morphemes don't spell the word letter for letter: irregular forms (PL, PST), bases
+// whose spelling changed (swimmer, happiness) and suffixes sharing a letter with the base
+// (rise + r + s) have no clean boundaries to ask about.
+func (g *MorphGenerator) Segments(w WordForm) ([]string, bool) {
+	var segs []string
+	for _, m := range w.Morphemes {
+		if m.Role == "affix" && m.Position == "" {
+			return nil, false
+		}
+		if m.Position == "suffix" && len(segs) > 0 && g.overlaps(segs[len(segs)-1], m.Surface) {
+			return nil, false
+		}
+		segs = append(segs, m.Surface)
+	}
+	return segs, len(segs) > 0 && strings.Join(segs, "") == w.Surface
+}
+
+// Whether a suffix allomorph lost letters the stem already ends in (rise + r, where -er's "e"
+// is the stem's)
+func (g *MorphGenerator) overlaps(stem, surface string) bool {
+	for _, r := range g.affixes.Positioned("suffix") {
+		if len(r.Surface) > len(surface) && strings.HasSuffix(r.Surface, surface) &&
+			strings.HasSuffix(stem, strings.TrimSuffix(r.Surface, surface)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Character offsets of the boundaries between segments (walk, er, s -> 4, 6)
+func boundariesOf(segs []string) []int {
+	var out []int
+	pos := 0
+	for _, s := range segs[:len(segs)-1] {
+		pos += len(s)
+		out = append(out, pos)
+	}
+	return out
+}
+
+// Cut word at the given boundary offsets
+func cut(word string, boundaries []int) []string {
+	var segs []string
+	prev := 0
+	for _, b := range boundaries {
+		segs = append(segs, word[prev:b])
+		prev = b
+	}
+	return append(segs, word[prev:])
+}
+
+// FormatSegmentation writes segments the way answers are shown: "walk + er + s"
+func FormatSegmentation(segs []string) string {
+	return strings.Join(segs, " + ")
+}
+
+// ParseSegmentation reads a typed segmentation. Any run of non-letters ("+", "-", spaces)
+// is one boundary, so "walk+er+s", "walk - er - s" and "Walk + er + s" all read the same.
+func ParseSegmentation(s string) (letters string, boundaries []int) {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(s) {
+		if r < 'a' || r > 'z' {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			boundaries = append(boundaries, b.Len())
+			pending = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), boundaries
+}
+
+// Result of grading a free-response segmentation
+type SegmentationGrade struct {
+	Correct  bool  `json:"correct"`
+	Misspelt bool  `json:"misspelt,omitempty"` // The answer's letters don't spell the word
+	Missing  []int `json:"missing,omitempty"`  // Boundaries the answer left out (offsets into the word)
+	Extra    []int `json:"extra,omitempty"`    // Boundaries the answer added
+}
+
+// GradeSegmentation compares a typed segmentation with the expected one by boundary position
+func GradeSegmentation(expected, given string) SegmentationGrade {
+	word, want := ParseSegmentation(expected)
+	letters, got := ParseSegmentation(given)
+	if letters != word {
+		return SegmentationGrade{Misspelt: true}
+	}
+
+	var grade SegmentationGrade
+	for _, b := range want {
+		if !slices.Contains(got, b) {
+			grade.Missing = append(grade.Missing, b)
+		}
+	}
+	for _, b := range got {
+		if !slices.Contains(want, b) {
+			grade.Extra = append(grade.Extra, b)
+		}
+	}
+	grade.Correct = len(grade.Missing) == 0 && len(grade.Extra) == 0
+	return grade
+}
+
+// A wrong segmentation and the rule code it breaks
+type misSegmentation struct {
+	Segments []string
+	Violated string
+}
+
+// Plausible wrong segmentations of word, made by moving, dropping or adding boundaries around
+// the real ones (walk + er + s -> wal + ker + s, walker + s, wal + kers, wa + lk + er + s).
+// Grouped by kind so a question can mix them.
+func (g *MorphGenerator) misSegmentations(word string, boundaries []int) map[string][]misSegmentation {
+	out := map[string][]misSegmentation{}
+	seen := map[string]bool{FormatSegmentation(cut(word, boundaries)): true}
+	add := func(kind string, bs []int) {
+		for i, b := range bs {
+			if b <= 0 || b >= len(word) || (i > 0 && b <= bs[i-1]) {
+				return
+			}
+		}
+		segs := cut(word, bs)
+		if key := FormatSegmentation(segs); !seen[key] {
+			seen[key] = true
+			out[kind] = append(out[kind], misSegmentation{Segments: segs, Violated: kind})
+		}
+	}
+
+	for i := range boundaries {
+		add("boundary_missing", slices.Delete(slices.Clone(boundaries), i, i+1))
+		for _, d := range []int{-2, -1, 1, 2} {
+			shifted := slices.Clone(boundaries)
+			shifted[i] += d
+			add("boundary_shifted", shifted)
+			for j := range shifted {
+				if j != i {
+					add("boundary_shifted_and_missing", slices.Delete(slices.Clone(shifted), j, j+1))
+				}
+			}
+		}
+	}
+	for b := 2; b < len(word)-1; b++ {
+		// Splitting off a real word or prefix (en + joy) could be defended as correct
+		if !slices.Contains(boundaries, b) && !g.looksLikeMorpheme(word, boundaries, b) {
+			extra := append(slices.Clone(boundaries), b)
+			slices.Sort(extra)
+			add("extra_boundary", extra)
+		}
+	}
+	return out
+}
+
+// Whether a new boundary at b would cut a known word or prefix out of its segment
+func (g *MorphGenerator) looksLikeMorpheme(word string, boundaries []int, b int) bool {
+	start, end := 0, len(word)
+	for _, x := range boundaries {
+		if x < b {
+			start = x
+		} else if end == len(word) {
+			end = x
+		}
+	}
+	for _, piece := range []string{word[start:b], word[b:end]} {
+		if _, ok := g.lex.Entry(piece); ok || g.affixes.isPrefixSurface(piece) {
+			return true
+		}
+	}
+	return false
+}
+
+// Builds a word of two or more morphemes whose boundaries can be read straight off its
+// spelling: walk + er + s, re + paint + ed, hope + less + ness
+func (g *MorphGenerator) segmentable() (WordForm, []string, bool) {
+	prefixed := g.attestedPairs("prefix")
+	suffixed := g.attestedPairs("suffix")
+	for range 50 {
+		var w WordForm
+		switch g.rng.Intn(4) {
+		case 0:
+			w = g.Pluralize(g.DeriveER(g.BaseForm(g.pickVerb())))
+		case 1:
+			if len(prefixed) == 0 {
+				continue
+			}
+			p := prefixed[g.rng.Intn(len(prefixed))]
+			w = g.inflectForSegmentation(g.DerivePrefix(g.BaseForm(p.Base), p.Affix))
+		case 2:
+			if len(suffixed) == 0 {
+				continue
+			}
+			p := suffixed[g.rng.Intn(len(suffixed))]
+			w = g.inflectForSegmentation(g.Apply(g.BaseForm(p.Base), p.Affix))
+		case 3:
+			w = g.inflectForSegmentation(g.BaseForm(g.pickAdj()))
+		}
+		if segs, ok := g.Segments(w); ok && len(segs) >= 2 && g.knownRoots(w) {
+			return w, segs, true
+		}
+	}
+	return WordForm{}, nil, false
+}
+
+// Roots must be annotated words (or compound roots) that don't look complex themselves: a
+// bank word like motionless or soaked would hide morphemes of its own
+func (g *MorphGenerator) knownRoots(w WordForm) bool {
+	for _, m := range w.Morphemes {
+		if m.Role != "root" {
+			continue
+		}
+		if _, ok := g.lex.part(m.Surface); !ok || !g.simpleRoot(m.Surface) {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether a root shows no sign of being built from smaller pieces: no recorded or guessed
+// derivation, no compound split, no derivational ending and no affix around a known word
+func (g *MorphGenerator) simpleRoot(root string) bool {
+	if g.lex.derived(root) {
+		return false
+	}
+	if _, _, compound := g.lex.Compound(root); compound {
+		return false
+	}
+	for _, cue := range suffixCues {
+		if cue.confidence >= 0.7 && len(root) > len(cue.suffix)+2 && strings.HasSuffix(root, cue.suffix) {
+			return false
+		}
+	}
+	for _, r := range g.affixes.Rules() {
+		var rest string
+		switch {
+		case r.Position == "suffix" && strings.HasSuffix(root, r.Surface):
+			rest = strings.TrimSuffix(root, r.Surface)
+		case r.Position == "prefix" && strings.HasPrefix(root, r.Surface):
+			rest = strings.TrimPrefix(root, r.Surface)
+		default:
+			continue
+		}
+		if _, ok := g.lex.Entry(rest); ok && len(rest) >= 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// Adds one more suffix the word's category (and shape) accepts: painted, kindness, payments
+func (g *MorphGenerator) inflectForSegmentation(w WordForm) WordForm {
+	var names []string
+	switch w.Category {
+	case POSVerb:
+		names = []string{"past", "progressive", "third_person"}
+	case POSAdjective:
+		names = []string{"ness", "comparative", "superlative"}
+	case POSNoun:
+		names = []string{"plural"}
+	}
+	var fits []string
+	for _, name := range names {
+		if r, ok := g.affixes.Rule(name); ok && r.Accepts(w.Category, w.Surface) {
+			fits = append(fits, name)
+		}
+	}
+	if len(fits) == 0 {
+		return w
+	}
+	return g.Apply(w, fits[g.rng.Intn(len(fits))])
+}
@@ -0,0 +1,61 @@
+/* Tests for reading and grading typed segmentations */
+
+package morphology
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSegmentation(t *testing.T) {
+	tests := []struct {
+		in             string
+		wantLetters    string
+		wantBoundaries []int
+	}{
+		{"walk + er + s", "walkers", []int{4, 6}},
+		{"walk+er+s", "walkers", []int{4, 6}},
+		{"walk - er - s", "walkers", []int{4, 6}},
+		{"Walk + ER + s", "walkers", []int{4, 6}},
+		{"walk  ++ er", "walker", []int{4}}, // A run of separators is one boundary
+		{"+ walk + er +", "walker", []int{4}},
+		{"re+paint+ed", "repainted", []int{2, 7}},
+		{"walkers", "walkers", nil},
+		{"", "", nil},
+		{" + ", "", nil},
+	}
+	for _, tt := range tests {
+		letters, boundaries := ParseSegmentation(tt.in)
+		if letters != tt.wantLetters || !slices.Equal(boundaries, tt.wantBoundaries) {
+			t.Errorf("ParseSegmentation(%q) = %q, %v, want %q, %v", tt.in, letters, boundaries, tt.wantLetters, tt.wantBoundaries)
+		}
+	}
+}
+
+func TestGradeSegmentation(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		given    string
+		want     SegmentationGrade
+	}{
+		{"exact", "walk + er + s", "walk + er + s", SegmentationGrade{Correct: true}},
+		{"other separators and case", "walk + er + s", "WALK-er-s", SegmentationGrade{Correct: true}},
+		{"boundary left out", "walk + er + s", "walker + s", SegmentationGrade{Missing: []int{4}}},
+		{"boundary added", "walk + er + s", "wa + lk + er + s", SegmentationGrade{Extra: []int{2}}},
+		{"boundary moved", "walk + er + s", "wal + ker + s", SegmentationGrade{Missing: []int{4}, Extra: []int{3}}},
+		{"no boundaries", "hope + less + ness", "hopelessness", SegmentationGrade{Missing: []int{4, 8}}},
+		{"misspelt", "walk + er + s", "wolk + er + s", SegmentationGrade{Misspelt: true}},
+		{"letter missing", "walk + er + s", "walk + er", SegmentationGrade{Misspelt: true}},
+		{"empty answer", "walk + er + s", "", SegmentationGrade{Misspelt: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GradeSegmentation(tt.expected, tt.given)
+			if got.Correct != tt.want.Correct || got.Misspelt != tt.want.Misspelt ||
+				!slices.Equal(got.Missing, tt.want.Missing) || !slices.Equal(got.Extra, tt.want.Extra) {
+				t.Errorf("GradeSegmentation(%q, %q) = %+v, want %+v", tt.expected, tt.given, got, tt.want)
+			}
+		})
+	}
+}
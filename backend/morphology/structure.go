@@ -0,0 +1,253 @@
+/* Hierarchical word structure: derivation trees, bracketings and attachment order */
+
+package morphology
+
+import (
+	"slices"
+	"strings"
+)
+
+// A node in a word's derivation tree. Leaves are morphemes; every inner node is the word one
+// affix built, with the base and the affix as its children in surface order:
+//
+//	[[un-lock]-able]  =  unlockable -> (unlock -> (un, lock), able)
+type TreeNode struct {
+	Surface  string      `bson:"surface" json:"surface"`
+	Category string      `bson:"category,omitempty" json:"category,omitempty"` // Category of the word the node spells
+	Role     string      `bson:"role,omitempty" json:"role,omitempty"`         // root|affix (leaves only)
+	Affix    string      `bson:"affix,omitempty" json:"affix,omitempty"`       // Affix rule name (affix leaves only)
+	Children []*TreeNode `bson:"children,omitempty" json:"children,omitempty"`
+}
+
+// Bracket writes the tree as unlabelled brackets: [[un-lock]-able]
+func (n *TreeNode) Bracket() string {
+	if len(n.Children) == 0 {
+		return n.Surface
+	}
+	var parts []string
+	for _, c := range n.Children {
+		parts = append(parts, c.Bracket())
+	}
+	return "[" + strings.Join(parts, "-") + "]"
+}
+
+// The tree a word was built with, or a single root leaf for words put together by hand
+func (w WordForm) tree() *TreeNode {
+	if w.Tree != nil {
+		return w.Tree
+	}
+	return &TreeNode{Surface: w.Surface, Category: w.Category, Role: "root"}
+}
+
+// The root (a leaf, or a compound's node) and the affix leaves in the order they were
+// attached (innermost first)
+func attachments(n *TreeNode) (*TreeNode, []*TreeNode) {
+	if len(n.Children) == 0 {
+		return n, nil
+	}
+	var stem, affix *TreeNode
+	for _, c := range n.Children {
+		if c.Role == "affix" {
+			affix = c
+		} else {
+			stem = c
+		}
+	}
+	if affix == nil {
+		return n, nil // A compound: both children are roots
+	}
+	root, order := attachments(stem)
+	return root, append(order, affix)
+}
+
+// Whether an affix leaf is a prefix
+func (g *MorphGenerator) isPrefix(a *TreeNode) bool {
+	r, ok := g.affixes.Rule(a.Affix)
+	return ok && r.Position == "prefix"
+}
+
+// Display form of an affix leaf: "un-" or "-ness"
+func (g *MorphGenerator) affixLabel(a *TreeNode) string {
+	if g.isPrefix(a) {
+		return a.Surface + "-"
+	}
+	return "-" + a.Surface
+}
+
+// Build the bracketing for attaching affixes to root in the given order
+func bracketing(root *TreeNode, order []*TreeNode, prefix func(*TreeNode) bool) *TreeNode {
+	cur := root
+	for _, a := range order {
+		if prefix(a) {
+			cur = &TreeNode{Children: []*TreeNode{a, cur}}
+		} else {
+			cur = &TreeNode{Children: []*TreeNode{cur, a}}
+		}
+	}
+	return cur
+}
+
+// Every order the affixes could attach in that keeps each side's linear order (un-kind-ness:
+// un then ness, or ness then un). Prefixes and suffixes are listed innermost first.
+func interleavings(prefixes, suffixes []*TreeNode) [][]*TreeNode {
+	if len(prefixes) == 0 {
+		return [][]*TreeNode{slices.Clone(suffixes)}
+	}
+	if len(suffixes) == 0 {
+		return [][]*TreeNode{slices.Clone(prefixes)}
+	}
+	var out [][]*TreeNode
+	for _, rest := range interleavings(prefixes[1:], suffixes) {
+		out = append(out, append([]*TreeNode{prefixes[0]}, rest...))
+	}
+	for _, rest := range interleavings(prefixes, suffixes[1:]) {
+		out = append(out, append([]*TreeNode{suffixes[0]}, rest...))
+	}
+	return out
+}
+
+// Checks an attachment order against the affixes' selectional restrictions. violated is empty
+// for a well-formed order, otherwise the rule the first bad step breaks.
+func (g *MorphGenerator) checkOrder(root *TreeNode, order []*TreeNode) (violated string) {
+	cats := g.lex.Categories(root.Surface)
+	stem := root.Surface
+	inflected := false
+	for _, a := range order {
+		r, ok := g.affixes.Rule(a.Affix)
+		if !ok {
+			return "unknown_affix"
+		}
+		if inflected {
+			return "inflection_not_outermost"
+		}
+		var next []string
+		for _, c := range cats {
+			if r.Accepts(c, stem) && !slices.Contains(next, r.OutputOf(c)) {
+				next = append(next, r.OutputOf(c))
+			}
+		}
+		if len(next) == 0 {
+			return "wrong_base_category"
+		}
+		cats = next
+		inflected = r.Type == "inflectional"
+		if r.Position == "prefix" {
+			stem = a.Surface + stem
+		} else {
+			stem += a.Surface
+		}
+	}
+	return ""
+}
+
+// A wrong bracketing of a word and the rule code it breaks
+type misBracketing struct {
+	Tree     *TreeNode
+	Violated string
+}
+
+// The other ways w could be bracketed. ok is false unless the real structure is the only
+// well-formed one (unlockable is ambiguous: [[un-lock]-able] and [un-[lock-able]] both work).
+func (g *MorphGenerator) misBracketings(w WordForm) ([]misBracketing, bool) {
+	root, order := attachments(w.tree())
+	if g.checkOrder(root, order) != "" {
+		return nil, false
+	}
+	// Each side's affixes, innermost first
+	var prefixes, suffixes []*TreeNode
+	for _, a := range order {
+		if g.isPrefix(a) {
+			prefixes = append(prefixes, a)
+		} else {
+			suffixes = append(suffixes, a)
+		}
+	}
+
+	var out []misBracketing
+	for _, alt := range interleavings(prefixes, suffixes) {
+		if slices.Equal(alt, order) {
+			continue
+		}
+		violated := g.checkOrder(root, alt)
+		if violated == "" {
+			return nil, false
+		}
+		out = append(out, misBracketing{Tree: bracketing(root, alt, g.isPrefix), Violated: violated})
+	}
+	return out, len(out) > 0
+}
+
+// Flat notation with no inner structure: [un-kind-ness]
+func (g *MorphGenerator) flatBracketing(w WordForm) string {
+	root, order := attachments(w.tree())
+	var prefixes, suffixes []string
+	for _, a := range order {
+		if g.isPrefix(a) {
+			prefixes = append([]string{a.Surface}, prefixes...)
+		} else {
+			suffixes = append(suffixes, a.Surface)
+		}
+	}
+	return "[" + strings.Join(slices.Concat(prefixes, []string{root.Surface}, suffixes), "-") + "]"
+}
+
+// Builds a word with a prefix and at least one suffix whose structure only one bracketing
+// allows: un + kind + ness, re + pack + er + s
+func (g *MorphGenerator) bracketable() (WordForm, []misBracketing, bool) {
+	// Start from either side equally often, so the prefix isn't nearly always the inner affix
+	sides := [][]affixPair{g.attestedPairs("prefix"), g.attestedPairs("suffix")}
+	if len(sides[0]) == 0 && len(sides[1]) == 0 {
+		return WordForm{}, nil, false
+	}
+	for range 50 {
+		pairs := sides[g.rng.Intn(2)]
+		if len(pairs) == 0 {
+			continue
+		}
+		p := pairs[g.rng.Intn(len(pairs))]
+		w := g.Apply(g.BaseForm(p.Base), p.Affix)
+		first, _ := g.affixes.Rule(p.Affix)
+
+		// A second derivational affix on the other side
+		var fits []string
+		for _, r := range g.affixes.Rules() {
+			if r.Type != "derivational" || r.Position == first.Position || !r.Accepts(w.Category, w.Surface) {
+				continue
+			}
+			// Attested on the root (un + kind + ness) or on the derived word (un + faithful)
+			if r.Attested && !slices.Contains(g.lex.Attested(r.Name), p.Base) && !slices.Contains(g.lex.Attested(r.Name), w.Surface) {
+				continue
+			}
+			fits = append(fits, r.Name)
+		}
+		if len(fits) == 0 {
+			continue
+		}
+		w = g.Apply(w, fits[g.rng.Intn(len(fits))])
+		if g.rng.Intn(2) == 0 {
+			w = g.inflectOnly(w)
+		}
+
+		if _, ok := g.Segments(w); !ok || !g.knownRoots(w) {
+			continue
+		}
+		if alts, ok := g.misBracketings(w); ok {
+			return w, alts, true
+		}
+	}
+	return WordForm{}, nil, false
+}
+
+// Adds an inflectional suffix the word accepts, if there is one (repackers, unkinder)
+func (g *MorphGenerator) inflectOnly(w WordForm) WordForm {
+	var fits []string
+	for _, r := range g.affixes.Rules() {
+		if r.Type == "inflectional" && r.Position == "suffix" && r.Accepts(w.Category, w.Surface) {
+			fits = append(fits, r.Name)
+		}
+	}
+	if len(fits) == 0 {
+		return w
+	}
+	return g.Apply(w, fits[g.rng.Intn(len(fits))])
+}
@@ -0,0 +1,294 @@
+/* Question wording: a catalog of paraphrased templates per question, one set per instruction language */
+
+package morphology
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultLanguage is the instruction language questions are worded in unless another is chosen,
+// and the one used for any question a language has no templates for
+const DefaultLanguage = "en"
+
+// One way of wording a question, e.g. {"id": "shared_root.2", "text": "Which word is built on
+// the same root as \"{word}\"?"}
+type Template struct {
+	ID   string `json:"id"`   // Stored on questions (prefixed with the language) to compare paraphrases
+	Text string `json:"text"` // {name} placeholders are filled from the question's slots
+}
+
+// Values for a template's placeholders, by name
+type Slots map[string]string
+
+// The slots each question's family fills in: the only placeholders its templates can use
+var templateSlots = map[string][]string{
+	"morpheme_classification":       {"word", "morpheme", "property"},
+	"infl_vs_deriv":                 {},
+	"lex_category_change":           {"suffix", "word"},
+	"feature_encoding":              {"suffix", "word"},
+	"morpheme_counting":             {"word"},
+	"well_formedness":               {},
+	"allomorphy":                    {},
+	"phonological_allomorphy.pick":  {"function", "sound"},
+	"phonological_allomorphy.claim": {"function", "word", "sound"},
+	"irregularity":                  {},
+	"irregular_class":               {"function", "form", "forms"},
+	"irregular_example":             {"class"},
+	"correct_form.pick":             {"function", "word"},
+	"correct_form.claim":            {"form", "function", "word"},
+	"nonce_plural":                  {"word"},
+	"nonce_past":                    {"word", "progressive"},
+	"nonce_agent":                   {"word"},
+	"root_meaning.meaning":          {"word", "origin", "root"},
+	"root_meaning.root":             {"word", "meaning"},
+	"shared_root":                   {"word"},
+	"prefix_meaning.meaning":        {"word", "prefix"},
+	"prefix_meaning.prefix":         {"meaning"},
+	"prefix_identification":         {},
+	"prefix_selection":              {},
+	"derived_category":              {"suffix", "category", "base", "article", "claimed", "word"},
+	"homophonous_affix.pick":        {"suffix", "function"},
+	"homophonous_affix.claim":       {"word", "suffix", "function"},
+	"segmentation":                  {"word"},
+	"segmentation_free":             {"word"},
+	"bracketing.pick":               {"word"},
+	"bracketing.claim":              {"bracketing", "word"},
+	"attachment_order.pick":         {"word"},
+	"attachment_order.claim":        {"word", "first", "second"},
+	"compound_identification":       {},
+	"compound_head":                 {"word"},
+	"compound_roots":                {"word"},
+}
+
+// TemplateCatalog holds every language's templates, keyed by the question they word: a family
+// name, or family.variant for families that ask more than one kind of question
+// (bracketing.pick, bracketing.claim). Each language is one file in data/templates, which
+// also labels the choices questions offer (true, irregular.ablaut, gloss.past).
+type TemplateCatalog struct {
+	languages map[string]map[string][]Template
+	labels    map[string]map[string]string
+}
+
+//go:embed data/templates/*.json
+var builtinTemplates embed.FS
+
+// DefaultTemplates returns the built-in catalog
+func DefaultTemplates() *TemplateCatalog {
+	c := &TemplateCatalog{languages: map[string]map[string][]Template{}, labels: map[string]map[string]string{}}
+	files, err := builtinTemplates.ReadDir("data/templates")
+	if err != nil {
+		panic("morphology: bad built-in templates: " + err.Error())
+	}
+	for _, f := range files {
+		data, err := builtinTemplates.ReadFile("data/templates/" + f.Name())
+		if err != nil {
+			panic("morphology: bad built-in templates: " + err.Error())
+		}
+		language, templates, labels, err := parseTemplates(data)
+		if err != nil {
+			panic("morphology: bad built-in templates: " + f.Name() + ": " + err.Error())
+		}
+		c.languages[language] = templates
+		c.labels[language] = labels
+	}
+	return c
+}
+
+// LoadTemplates reads a template file on top of the built-in catalog: for a language the
+// catalog has, its keys and labels replace the built-in ones; any other language is added.
+func LoadTemplates(path string) (*TemplateCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	language, templates, labels, err := parseTemplates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	c := DefaultTemplates()
+	if c.languages[language] == nil {
+		c.languages[language] = map[string][]Template{}
+		c.labels[language] = map[string]string{}
+	}
+	for key, t := range templates {
+		c.languages[language][key] = t
+	}
+	for key, l := range labels {
+		c.labels[language][key] = l
+	}
+	return c, nil
+}
+
+func parseTemplates(data []byte) (string, map[string][]Template, map[string]string, error) {
+	var file struct {
+		Language  string                `json:"language"`
+		Templates map[string][]Template `json:"templates"`
+		Labels    map[string]string     `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return "", nil, nil, err
+	}
+	if file.Language == "" {
+		return "", nil, nil, fmt.Errorf("language is required")
+	}
+	seen := map[string]bool{}
+	for key, templates := range file.Templates {
+		slots, ok := templateSlots[key]
+		if !ok {
+			return "", nil, nil, fmt.Errorf("%q is not a question the generator asks", key)
+		}
+		if len(templates) == 0 {
+			return "", nil, nil, fmt.Errorf("%q has no templates", key)
+		}
+		for _, t := range templates {
+			if t.ID == "" || t.Text == "" {
+				return "", nil, nil, fmt.Errorf("%q: every template needs an id and text", key)
+			}
+			if seen[t.ID] {
+				return "", nil, nil, fmt.Errorf("template id %q is used twice", t.ID)
+			}
+			seen[t.ID] = true
+			names, err := placeholders(t.Text)
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("template %q: %w", t.ID, err)
+			}
+			for _, name := range names {
+				if !slices.Contains(slots, name) {
+					return "", nil, nil, fmt.Errorf("template %q: unknown placeholder {%s} (%q has %s)", t.ID, name, key, describeSlots(slots))
+				}
+			}
+		}
+	}
+	for key, l := range file.Labels {
+		if l == "" {
+			return "", nil, nil, fmt.Errorf("label %q is empty", key)
+		}
+	}
+	if file.Labels == nil {
+		file.Labels = map[string]string{}
+	}
+	return file.Language, file.Templates, file.Labels, nil
+}
+
+// Languages lists the instruction languages the catalog has templates for
+func (c *TemplateCatalog) Languages() []string {
+	var out []string
+	for l := range c.languages {
+		out = append(out, l)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// The templates for a question in a language, falling back to the default language, and the
+// language they are in
+func (c *TemplateCatalog) lookup(language, key string) (string, []Template) {
+	if t := c.languages[language][key]; len(t) > 0 {
+		return language, t
+	}
+	return DefaultLanguage, c.languages[DefaultLanguage][key]
+}
+
+// A choice's label in a language, falling back to the default language ("" when neither has one)
+func (c *TemplateCatalog) label(language, key string) string {
+	if l := c.labels[language][key]; l != "" {
+		return l
+	}
+	return c.labels[DefaultLanguage][key]
+}
+
+// The placeholder names in a template, catching malformed ones at load time ("{word", "{}", "word}")
+func placeholders(text string) ([]string, error) {
+	var names []string
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			if strings.ContainsRune(text, '}') {
+				return nil, fmt.Errorf("unmatched \"}\"")
+			}
+			return names, nil
+		}
+		if strings.ContainsRune(text[:open], '}') {
+			return nil, fmt.Errorf("unmatched \"}\"")
+		}
+		end := strings.IndexByte(text[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unmatched \"{\"")
+		}
+		name := text[open+1 : open+end]
+		if name == "" || strings.ContainsRune(name, '{') {
+			return nil, fmt.Errorf("bad placeholder %q", text[open:open+end+1])
+		}
+		names = append(names, name)
+		text = text[open+end+1:]
+	}
+}
+
+// The slots a question has, for error messages
+func describeSlots(slots []string) string {
+	if len(slots) == 0 {
+		return "no slots"
+	}
+	return "{" + strings.Join(slots, "}, {") + "}"
+}
+
+// Fill a template's placeholders. Templates are checked against their family's slots when
+// they load; a placeholder with no value is left as it is.
+func fill(text string, slots Slots) string {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		end := open + strings.IndexByte(text[open:], '}')
+		name := text[open+1 : end]
+		b.WriteString(text[:open])
+		if value, ok := slots[name]; ok {
+			b.WriteString(value)
+		} else {
+			b.WriteString(text[open : end+1])
+		}
+		text = text[end+1:]
+	}
+}
+
+// How a question is worded. The text the question's first template would give is kept (not
+// stored) so bank generation can tell the same question worded two ways apart from a new one.
+type Prompt struct {
+	QuestionText string `bson:"question_text" json:"question_text"`
+	TemplateID   string `bson:"template_id" json:"template_id"` // language/id, e.g. en/shared_root.2
+
+	canonical string
+}
+
+// Word the question key with one of its templates in the generator's language, picked at random
+func (g *MorphGenerator) prompt(key string, slots Slots) Prompt {
+	language, templates := g.templates.lookup(g.language, key)
+	if len(templates) == 0 {
+		panic("morphology: no templates for " + key)
+	}
+	t := templates[g.rng.Intn(len(templates))]
+	return Prompt{
+		QuestionText: fill(t.Text, slots),
+		TemplateID:   language + "/" + t.ID,
+		canonical:    fill(templates[0].Text, slots),
+	}
+}
+
+// Label a fixed choice in the generator's language. Every fixed choice needs a label: a missing
+// one is a bug in the template files, like a missing template.
+func (g *MorphGenerator) label(key string) string {
+	l := g.templates.label(g.language, key)
+	if l == "" {
+		panic("morphology: no label for " + key)
+	}
+	return l
+}
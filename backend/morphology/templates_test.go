@@ -0,0 +1,98 @@
+/* Tests for loading question templates and filling their placeholders */
+
+package morphology
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTemplatesPlaceholders(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string // Part of the error, "" when the file should load
+	}{
+		{"known slots", `{"language": "fr", "templates": {"shared_root": [{"id": "s.1", "text": "Quel mot partage une racine avec « {word} » ?"}]}}`, ""},
+		{"labels only", `{"language": "fr", "labels": {"true": "Vrai"}}`, ""},
+		{"misspelt slot", `{"language": "fr", "templates": {"shared_root": [{"id": "s.1", "text": "Racine de « {wrod} » ?"}]}}`, "unknown placeholder {wrod}"},
+		{"slot of another family", `{"language": "fr", "templates": {"shared_root": [{"id": "s.1", "text": "{word} : {suffix} ?"}]}}`, "unknown placeholder {suffix}"},
+		{"family without slots", `{"language": "fr", "templates": {"allomorphy": [{"id": "a.1", "text": "Lequel : {word} ?"}]}}`, "no slots"},
+		{"unknown question", `{"language": "fr", "templates": {"shared_roots": [{"id": "s.1", "text": "{word}"}]}}`, "not a question"},
+		{"unmatched brace", `{"language": "fr", "templates": {"shared_root": [{"id": "s.1", "text": "{word"}]}}`, "unmatched"},
+		{"empty label", `{"language": "fr", "labels": {"true": ""}}`, "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := parseTemplates([]byte(tt.file))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("loaded, want an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("error %q, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// A template file with a misspelt slot is rejected when it loads, not when a question uses it
+func TestLoadTemplatesUnknownSlot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "en.json")
+	file := `{"language": "en", "templates": {"compound_roots": [{"id": "c.1", "text": "How many roots does \"{wrod}\" have?"}]}}`
+	if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(path); err == nil || !strings.Contains(err.Error(), "{wrod}") {
+		t.Errorf("LoadTemplates = %v, want an unknown placeholder error", err)
+	}
+}
+
+func TestFill(t *testing.T) {
+	tests := []struct {
+		text  string
+		slots Slots
+		want  string
+	}{
+		{`Which word shares a root with "{word}"?`, Slots{"word": "aquatic"}, `Which word shares a root with "aquatic"?`},
+		{"{first} before {second}", Slots{"first": "un-", "second": "-ness"}, "un- before -ness"},
+		{"No slots here.", nil, "No slots here."},
+		{"Left as is: {word}", nil, "Left as is: {word}"},
+	}
+	for _, tt := range tests {
+		if got := fill(tt.text, tt.slots); got != tt.want {
+			t.Errorf("fill(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// Every question has English templates, and every family fills each slot its templates use
+func TestTemplateSlotsMatchFamilies(t *testing.T) {
+	catalog := DefaultTemplates()
+	for key := range templateSlots {
+		if _, templates := catalog.lookup(DefaultLanguage, key); len(templates) == 0 {
+			t.Errorf("%q has no English templates", key)
+		}
+	}
+
+	entries, err := LoadWordBank(filepath.Join("..", "tools", "wordbank.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewMorphGenerator(NewLexicon(entries), rand.New(rand.NewSource(1)))
+	for _, family := range FamilyNames() {
+		docs, err := Sample(g, family, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, q := range docs {
+			if strings.ContainsAny(q.QuestionText, "{}") {
+				t.Errorf("%s: unfilled placeholder in %q", q.TemplateID, q.QuestionText)
+			}
+		}
+	}
+}
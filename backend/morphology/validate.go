@@ -0,0 +1,125 @@
+/* Structural checks every question in a bank must pass before it is published */
+
+package morphology
+
+import (
+	"fmt"
+)
+
+// A single problem found in a question
+type Issue struct {
+	QuestionID string `json:"question_id"`
+	Family     string `json:"family"`
+	Problem    string `json:"problem"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s (%s): %s", i.QuestionID, i.Family, i.Problem)
+}
+
+// ValidationError is returned when a bank fails validation
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("question bank failed validation with %d issue(s)", len(e.Issues))
+}
+
+// Validate checks a single question's structural invariants
+func Validate(q QuestionDoc) []string {
+	var problems []string
+
+	if q.ID == "" {
+		problems = append(problems, "missing id")
+	}
+	if q.Text != q.QuestionText {
+		problems = append(problems, "text and question_text differ")
+	}
+	if q.Answer != q.CorrectAnswer {
+		problems = append(problems, "answer and correct_answer differ")
+	}
+
+	// The answer must be one of the choices, exactly once (free-response questions have none)
+	answerCount := 0
+	for _, c := range q.Choices {
+		if c == q.CorrectAnswer {
+			answerCount++
+		}
+	}
+	if answerCount != 1 && q.QuestionType != "FR" {
+		problems = append(problems, fmt.Sprintf("answer %q appears %d times in choices", q.CorrectAnswer, answerCount))
+	}
+
+	// Choices must be distinct
+	seen := map[string]bool{}
+	for _, c := range q.Choices {
+		if seen[c] {
+			problems = append(problems, fmt.Sprintf("duplicate choice %q", c))
+		}
+		seen[c] = true
+	}
+
+	switch q.QuestionType {
+	case "MC":
+		if len(q.Choices) != 4 {
+			problems = append(problems, fmt.Sprintf("MC question has %d choices, want 4", len(q.Choices)))
+		}
+		if len(q.Distractors) != len(q.Choices)-1 {
+			problems = append(problems, fmt.Sprintf("%d distractors for %d choices", len(q.Distractors), len(q.Choices)))
+		}
+		if len(q.ViolatedRule) != len(q.Distractors) {
+			problems = append(problems, fmt.Sprintf("%d violated rules for %d distractors", len(q.ViolatedRule), len(q.Distractors)))
+		}
+	case "TF":
+		if len(q.Choices) != 2 {
+			problems = append(problems, fmt.Sprintf("TF question has %d choices, want 2", len(q.Choices)))
+		}
+		if len(q.ViolatedRule) > 1 {
+			problems = append(problems, fmt.Sprintf("TF question has %d violated rules, want at most 1", len(q.ViolatedRule)))
+		}
+	case "FR":
+		if len(q.Choices) != 0 || len(q.Distractors) != 0 {
+			problems = append(problems, fmt.Sprintf("FR question has %d choices and %d distractors, want none", len(q.Choices), len(q.Distractors)))
+		}
+		// The only free-response answers are segmentations, graded by boundary position
+		if word, boundaries := ParseSegmentation(q.CorrectAnswer); word == "" || len(boundaries) == 0 {
+			problems = append(problems, fmt.Sprintf("FR answer %q is not a segmentation", q.CorrectAnswer))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown question type %q", q.QuestionType))
+	}
+
+	return problems
+}
+
+// ValidateBank checks every question plus bank-wide invariants (unique ids). Template ids and
+// explanations are only required of banks from generators that write them, so a version
+// published before either existed still validates: a bank with any template id (or
+// explanation) needs one on every question.
+func ValidateBank(docs []QuestionDoc) []Issue {
+	templated, explained := false, false
+	for _, q := range docs {
+		templated = templated || q.TemplateID != ""
+		explained = explained || q.Explanation != ""
+	}
+
+	var issues []Issue
+	ids := map[string]bool{}
+	for _, q := range docs {
+		for _, p := range Validate(q) {
+			issues = append(issues, Issue{QuestionID: q.ID, Family: q.Family, Problem: p})
+		}
+		if templated && q.TemplateID == "" {
+			issues = append(issues, Issue{QuestionID: q.ID, Family: q.Family, Problem: "missing template id"})
+		}
+		if explained && q.Explanation == "" {
+			issues = append(issues, Issue{QuestionID: q.ID, Family: q.Family, Problem: "missing explanation"})
+		}
+		if q.ID != "" && ids[q.ID] {
+			issues = append(issues, Issue{QuestionID: q.ID, Family: q.Family, Problem: "duplicate id"})
+		}
+		ids[q.ID] = true
+	}
+	return issues
+}
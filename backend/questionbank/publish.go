@@ -0,0 +1,166 @@
+/* Versioned, idempotent publishing of generated question banks to MongoDB */
+
+package questionbank
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/morphology"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrVersionNotFound = errors.New("question bank version not found")
+
+// Metadata for one published bank version
+type Version struct {
+	Tag         string    `bson:"_id" json:"tag"`
+	PublishedAt time.Time `bson:"published_at" json:"publishedAt"`
+	Count       int       `bson:"count" json:"count"`
+	Seed        int64     `bson:"seed,omitempty" json:"seed,omitempty"`
+	Active      bool      `bson:"active" json:"active"`
+}
+
+// Question documents carry two fields managed here rather than by the generator:
+//   versions: every bank version tag the question belongs to
+//   active:   true iff the question is in the active version (the server only serves these)
+
+func questions(client *mongo.Client) *mongo.Collection {
+	return client.Database("capymorphDB").Collection("questions")
+}
+
+func versions(client *mongo.Client) *mongo.Collection {
+	return client.Database("capymorphDB").Collection("question_banks")
+}
+
+// EnsureIndexes creates the unique index on question ids. Legacy documents with numeric ids
+// are left out of the index so they don't block it.
+func EnsureIndexes(client *mongo.Client) error {
+	_, err := questions(client).Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"id": bson.M{"$type": "string"}}),
+		},
+		{Keys: bson.D{{Key: "active", Value: 1}}},
+	})
+	return err
+}
+
+// Publish validates the bank, upserts every question by id, tags it with the version and makes
+// that version active. Re-publishing the same tag replaces its membership, so reruns are idempotent.
+func Publish(client *mongo.Client, tag string, seed int64, docs []morphology.QuestionDoc) error {
+	if tag == "" {
+		return fmt.Errorf("a version tag is required")
+	}
+	if len(docs) == 0 {
+		return fmt.Errorf("nothing to publish")
+	}
+	if issues := morphology.ValidateBank(docs); len(issues) > 0 {
+		return &morphology.ValidationError{Issues: issues}
+	}
+	if err := EnsureIndexes(client); err != nil {
+		return err
+	}
+
+	// Drop any earlier membership of this tag so the version matches docs exactly
+	if _, err := questions(client).UpdateMany(context.TODO(), bson.M{"versions": tag}, bson.M{"$pull": bson.M{"versions": tag}}); err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for _, q := range docs {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": q.ID}).
+			SetUpdate(bson.M{"$set": q, "$addToSet": bson.M{"versions": tag}, "$setOnInsert": bson.M{"active": false}}).
+			SetUpsert(true))
+	}
+	if _, err := questions(client).BulkWrite(context.TODO(), models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return err
+	}
+
+	_, err := versions(client).UpdateOne(context.TODO(),
+		bson.M{"_id": tag},
+		bson.M{"$set": bson.M{"published_at": time.Now().UTC(), "count": len(docs), "seed": seed}, "$setOnInsert": bson.M{"active": false}},
+		options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return Activate(client, tag)
+}
+
+// Activate makes tag the live version: its questions are served, every other question is retired.
+// Rolling back is activating an older tag.
+func Activate(client *mongo.Client, tag string) error {
+	n, err := versions(client).CountDocuments(context.TODO(), bson.M{"_id": tag})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrVersionNotFound
+	}
+
+	// Bring the new version in before retiring the old one so there's never an empty bank
+	if _, err := questions(client).UpdateMany(context.TODO(), bson.M{"versions": tag}, bson.M{"$set": bson.M{"active": true}}); err != nil {
+		return err
+	}
+	if _, err := questions(client).UpdateMany(context.TODO(), bson.M{"versions": bson.M{"$ne": tag}}, bson.M{"$set": bson.M{"active": false}}); err != nil {
+		return err
+	}
+
+	if _, err := versions(client).UpdateMany(context.TODO(), bson.M{"_id": bson.M{"$ne": tag}}, bson.M{"$set": bson.M{"active": false}}); err != nil {
+		return err
+	}
+	_, err = versions(client).UpdateOne(context.TODO(), bson.M{"_id": tag}, bson.M{"$set": bson.M{"active": true}})
+	return err
+}
+
+// ListVersions returns every published version, newest first
+func ListVersions(client *mongo.Client) ([]Version, error) {
+	cursor, err := versions(client).Find(context.TODO(), bson.D{}, options.Find().SetSort(bson.D{{Key: "published_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var out []Version
+	if err := cursor.All(context.TODO(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HasVersions reports whether any bank version has been published
+func HasVersions(client *mongo.Client) (bool, error) {
+	n, err := versions(client).CountDocuments(context.TODO(), bson.D{}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+// Load reads the questions of a version (the active version when tag is empty)
+func Load(client *mongo.Client, tag string) ([]morphology.QuestionDoc, error) {
+	filter := bson.M{"active": true}
+	if tag != "" {
+		filter = bson.M{"versions": tag}
+	}
+
+	cursor, err := questions(client).Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var out []morphology.QuestionDoc
+	if err := cursor.All(context.TODO(), &out); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrVersionNotFound
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+/* Tests for the checks Publish makes before it touches the database */
+
+package questionbank
+
+import (
+	"errors"
+	"testing"
+
+	"backend/morphology"
+)
+
+func TestPublishRejects(t *testing.T) {
+	broken := morphology.QuestionDoc{ID: "q1", QuestionType: "TF", CorrectAnswer: "True", Choices: []string{"True"}}
+
+	tests := []struct {
+		name           string
+		tag            string
+		docs           []morphology.QuestionDoc
+		wantValidation bool
+	}{
+		{"no tag", "", []morphology.QuestionDoc{broken}, false},
+		{"no questions", "v1", nil, false},
+		{"invalid bank", "v1", []morphology.QuestionDoc{broken}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No client: every rejection has to happen before the first write
+			err := Publish(nil, tt.tag, 1, tt.docs)
+			if err == nil {
+				t.Fatal("Publish accepted the bank")
+			}
+			var verr *morphology.ValidationError
+			if got := errors.As(err, &verr); got != tt.wantValidation {
+				t.Errorf("error %q: validation error = %v, want %v", err, got, tt.wantValidation)
+			}
+			if tt.wantValidation && len(verr.Issues) == 0 {
+				t.Error("validation error lists no issues")
+			}
+		})
+	}
+}
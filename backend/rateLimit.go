@@ -0,0 +1,103 @@
+/* Token-bucket rate limiting middleware for API routes */
+
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Keys untouched for this long are evicted from a limiter's bucket map
+const limiterIdleTTL = 10 * time.Minute
+
+// A single client's bucket
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter hands out tokens per key (client IP), refilling at a fixed rate up to a burst size
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens added per second
+	burst     float64 // bucket capacity
+	idleTTL   time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a limiter allowing `burst` requests at once and `perSecond` sustained
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:      perSecond,
+		burst:     float64(burst),
+		idleTTL:   limiterIdleTTL,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token for key if one is available. When the bucket is empty it
+// returns false and how long the caller must wait before the next token.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = b
+	} else {
+		// Refill based on time elapsed since the last request
+		elapsed := now.Sub(b.lastSeen).Seconds()
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+		b.lastSeen = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// Drop buckets that have been idle longer than idleTTL (at most once per TTL)
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware rejects requests over the limit with 429 and a Retry-After header (in whole seconds)
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := l.Allow(c.ClientIP())
+		if !ok {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, slow down"})
+			return
+		}
+		c.Next()
+	}
+}
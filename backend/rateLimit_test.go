@@ -0,0 +1,141 @@
+/* Tests for the token-bucket rate limiter: burst, refill, waits and the 429 middleware */
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pretend key's last request happened d earlier, as if that much time had passed
+func age(l *RateLimiter, key string, d time.Duration) {
+	l.buckets[key].lastSeen = l.buckets[key].lastSeen.Add(-d)
+}
+
+// Requests allowed in a row before the first refusal (stops counting at limit)
+func allowedInARow(l *RateLimiter, key string, limit int) int {
+	for n := 0; n < limit; n++ {
+		if ok, _ := l.Allow(key); !ok {
+			return n
+		}
+	}
+	return limit
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	tests := []struct {
+		perSecond float64
+		burst     int
+	}{
+		{1, 1},
+		{1, 5},
+		{10, 3},
+		{0.5, 20},
+	}
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.perSecond, tt.burst)
+		if got := allowedInARow(l, "a", tt.burst+10); got != tt.burst {
+			t.Errorf("rate %v burst %d: %d requests allowed at once, want %d", tt.perSecond, tt.burst, got, tt.burst)
+		}
+		if got := allowedInARow(l, "b", tt.burst+10); got != tt.burst {
+			t.Errorf("rate %v burst %d: another key got %d requests, want its own %d", tt.perSecond, tt.burst, got, tt.burst)
+		}
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	tests := []struct {
+		name      string
+		perSecond float64
+		burst     int
+		idle      time.Duration
+		want      int
+	}{
+		{"not a whole token yet", 1, 3, 500 * time.Millisecond, 0},
+		{"one token", 1, 3, 1500 * time.Millisecond, 1},
+		{"two tokens", 1, 3, 2500 * time.Millisecond, 2},
+		{"faster rate", 4, 5, 600 * time.Millisecond, 2},
+		{"capped at the burst", 1, 3, 100 * time.Second, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewRateLimiter(tt.perSecond, tt.burst)
+			allowedInARow(l, "a", tt.burst+1) // Empty the bucket
+			age(l, "a", tt.idle)
+			if got := allowedInARow(l, "a", tt.burst+10); got != tt.want {
+				t.Errorf("%d requests allowed after %v, want %d", got, tt.idle, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		perSecond float64
+		want      time.Duration
+	}{
+		{1, time.Second},
+		{2, 500 * time.Millisecond},
+		{0.25, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.perSecond, 1)
+		l.Allow("a")
+		ok, wait := l.Allow("a")
+		if ok {
+			t.Fatalf("rate %v: second request allowed with a burst of 1", tt.perSecond)
+		}
+		// A little time passes between the two calls, so the wait can be a bit shorter
+		if wait > tt.want || wait < tt.want-50*time.Millisecond {
+			t.Errorf("rate %v: wait %v, want about %v", tt.perSecond, wait, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	l.Allow("idle")
+	l.Allow("busy")
+	age(l, "idle", limiterIdleTTL+time.Minute)
+	l.lastSweep = l.lastSweep.Add(-limiterIdleTTL)
+
+	l.Allow("busy")
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("idle bucket was not evicted")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("busy bucket was evicted")
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", NewRateLimiter(0.25, 2).Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		wantStatus int
+		wantRetry  string
+	}{
+		{http.StatusOK, ""},
+		{http.StatusOK, ""},
+		{http.StatusTooManyRequests, "4"},
+		{http.StatusTooManyRequests, "4"},
+	}
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("request %d: status %d, want %d", i+1, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+			t.Errorf("request %d: Retry-After %q, want %q", i+1, got, tt.wantRetry)
+		}
+	}
+}
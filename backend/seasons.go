@@ -0,0 +1,280 @@
+/* Leaderboard seasons: active season lookup, end-of-season archival and rotation */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrSeasonNotFound = errors.New("season not found")
+	ErrSeasonOverlap  = errors.New("season overlaps an existing season")
+)
+
+// Structure of a season document in MongoDB
+type Season struct {
+	ID         string     `bson:"_id" json:"id"`
+	Name       string     `bson:"name" json:"name"`
+	StartAt    time.Time  `bson:"start_at" json:"startAt"`
+	EndAt      time.Time  `bson:"end_at" json:"endAt"`
+	ArchivedAt *time.Time `bson:"archived_at,omitempty" json:"archivedAt,omitempty"`
+}
+
+// Active reports whether t falls inside the season
+func (s *Season) Active(t time.Time) bool {
+	return !t.Before(s.StartAt) && t.Before(s.EndAt)
+}
+
+// Structure of an archived final standing
+type SeasonStanding struct {
+	SeasonID string        `bson:"season_id" json:"seasonId"`
+	Rank     int64         `bson:"rank" json:"rank"`
+	EntryID  bson.ObjectID `bson:"entry_id" json:"entryId"`
+	Username string        `bson:"username" json:"username"`
+	Score    int           `bson:"score" json:"score"`
+}
+
+// CreateSeason stores a new season, refusing ones that overlap an existing season
+func CreateSeason(client *mongo.Client, season Season) error {
+	collection := client.Database("capymorphDB").Collection("seasons")
+
+	overlapping, err := collection.CountDocuments(context.TODO(), bson.M{
+		"start_at": bson.M{"$lt": season.EndAt},
+		"end_at":   bson.M{"$gt": season.StartAt},
+	})
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrSeasonOverlap
+	}
+
+	_, err = collection.InsertOne(context.TODO(), season)
+	return err
+}
+
+// GetSeasons lists every season, newest first
+func GetSeasons(client *mongo.Client) ([]Season, error) {
+	collection := client.Database("capymorphDB").Collection("seasons")
+
+	cursor, err := collection.Find(context.TODO(), bson.D{}, options.Find().SetSort(bson.D{{Key: "start_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	seasons := []Season{}
+	if err := cursor.All(context.TODO(), &seasons); err != nil {
+		return nil, err
+	}
+	return seasons, nil
+}
+
+// GetSeason looks up a season by id
+func GetSeason(client *mongo.Client, id string) (*Season, error) {
+	collection := client.Database("capymorphDB").Collection("seasons")
+
+	var season Season
+	err := collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&season)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrSeasonNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// GetActiveSeason returns the season running at t, or nil if there is none
+func GetActiveSeason(client *mongo.Client, t time.Time) (*Season, error) {
+	collection := client.Database("capymorphDB").Collection("seasons")
+
+	var season Season
+	err := collection.FindOne(context.TODO(), bson.M{
+		"start_at": bson.M{"$lte": t},
+		"end_at":   bson.M{"$gt": t},
+	}).Decode(&season)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// GetLastEndedSeason returns the most recent season to have ended by t, or nil if none has
+func GetLastEndedSeason(client *mongo.Client, t time.Time) (*Season, error) {
+	collection := client.Database("capymorphDB").Collection("seasons")
+
+	var season Season
+	err := collection.FindOne(context.TODO(), bson.M{"end_at": bson.M{"$lte": t}},
+		options.FindOne().SetSort(bson.D{{Key: "end_at", Value: -1}})).Decode(&season)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// The season_id given to scores submitted between a season's end and the next one's start,
+// so the off-season board starts empty and survives restarts
+func offSeasonID(last *Season) string {
+	return "after:" + last.ID
+}
+
+// ArchiveEndedSeasons snapshots the final standings of every season that has ended but
+// was not yet archived. Entries stay in the leaderboards collection untouched.
+func ArchiveEndedSeasons(client *mongo.Client, now time.Time) error {
+	seasons := client.Database("capymorphDB").Collection("seasons")
+
+	cursor, err := seasons.Find(context.TODO(), bson.M{
+		"end_at":      bson.M{"$lte": now},
+		"archived_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return err
+	}
+	var ended []Season
+	if err := cursor.All(context.TODO(), &ended); err != nil {
+		return err
+	}
+
+	for _, season := range ended {
+		if err := archiveSeason(client, season.ID); err != nil {
+			return err
+		}
+		log.Printf("Archived final standings for season %q", season.ID)
+	}
+	return nil
+}
+
+func archiveSeason(client *mongo.Client, seasonID string) error {
+	db := client.Database("capymorphDB")
+
+	idx, err := LoadLeaderboardIndex(client, globalBoardFilter(seasonID))
+	if err != nil {
+		return err
+	}
+
+	// Rebuild from scratch so a retry after a partial failure doesn't duplicate rows
+	standingsCollection := db.Collection("season_standings")
+	if _, err := standingsCollection.DeleteMany(context.TODO(), bson.M{"season_id": seasonID}); err != nil {
+		return err
+	}
+
+	entries := idx.Top(idx.Len())
+	if len(entries) > 0 {
+		standings := make([]SeasonStanding, 0, len(entries))
+		for i, e := range entries {
+			// Competition ranking: ties share the rank of the first tied entry
+			rank := int64(i + 1)
+			if i > 0 && e.Score == entries[i-1].Score {
+				rank = standings[i-1].Rank
+			}
+			standings = append(standings, SeasonStanding{SeasonID: seasonID, Rank: rank, EntryID: e.ID, Username: e.Username, Score: e.Score})
+		}
+		if _, err := standingsCollection.InsertMany(context.TODO(), standings); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Collection("seasons").UpdateOne(context.TODO(), bson.M{"_id": seasonID}, bson.M{"$set": bson.M{"archived_at": time.Now().UTC()}})
+	return err
+}
+
+// GetSeasonStandings returns the top archived standings of a season
+func GetSeasonStandings(client *mongo.Client, seasonID string, limit int) ([]SeasonStanding, error) {
+	collection := client.Database("capymorphDB").Collection("season_standings")
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "rank", Value: 1}, {Key: "entry_id", Value: 1}})
+	findOptions.SetLimit(int64(limit))
+
+	cursor, err := collection.Find(context.TODO(), bson.M{"season_id": seasonID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	standings := []SeasonStanding{}
+	if err := cursor.All(context.TODO(), &standings); err != nil {
+		return nil, err
+	}
+	return standings, nil
+}
+
+// Serializes RefreshSeason: the season watcher and the admin handler both call it, and two
+// archives of the same season at once would interleave their delete and insert
+var seasonRefresh sync.Mutex
+
+// RefreshSeason archives ended seasons and, when the active season has changed, swaps in a
+// freshly loaded global board for it. current holds a *Season (nil when no season runs).
+// Between seasons the board holds only scores submitted since the last one ended; without
+// any seasons it is the all-time board.
+func RefreshSeason(client *mongo.Client, current, board *atomic.Value) error {
+	seasonRefresh.Lock()
+	defer seasonRefresh.Unlock()
+
+	now := time.Now().UTC()
+	if err := ArchiveEndedSeasons(client, now); err != nil {
+		return err
+	}
+
+	active, err := GetActiveSeason(client, now)
+	if err != nil {
+		return err
+	}
+
+	var nextID string
+	if active != nil {
+		nextID = active.ID
+	} else {
+		last, err := GetLastEndedSeason(client, now)
+		if err != nil {
+			return err
+		}
+		if last != nil {
+			nextID = offSeasonID(last)
+		}
+	}
+	current.Store(active)
+	prev, _ := board.Load().(*LeaderboardIndex)
+	if prev != nil && prev.SeasonID() == nextID {
+		return nil
+	}
+
+	// New season (or first load): the default board resets to the new season's entries
+	idx, err := LoadLeaderboardIndex(client, globalBoardFilter(nextID))
+	if err != nil {
+		return err
+	}
+	idx.season = nextID
+	board.Store(idx)
+	if prev != nil {
+		log.Printf("Leaderboard season is now %q (%d entries)", nextID, idx.Len())
+	}
+	return nil
+}
+
+// WatchSeasons calls RefreshSeason on an interval so boards roll over when a season ends
+func WatchSeasons(client *mongo.Client, current, board *atomic.Value, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := RefreshSeason(client, current, board); err != nil {
+			log.Println("Season refresh failed:", err)
+		}
+	}
+}
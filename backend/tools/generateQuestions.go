@@ -1,718 +1,465 @@
-/* Helper function to populate the DB with morphology questions */
+/* CLI to generate morphology questions and publish them to the DB (or preview/export them) */
 
 package main
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
-	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
-	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"backend/database"
+	"backend/morphology"
+	"backend/questionbank"
 )
 
-type Morpheme struct {
-	Surface   string            `bson:"surface" json:"surface"`
-	Role      string            `bson:"role" json:"role"` // root|affix
-	Bound     bool              `bson:"bound" json:"bound"`
-	MorphType string            `bson:"morph_type" json:"morph_type"` // inflectional|derivational
-	Features  map[string]string `bson:"features" json:"features"`
-}
+const usage = `Usage: generateQuestions [command] [flags]
 
-type WordForm struct {
-	Surface   string            `bson:"surface" json:"surface"`
-	Base      string            `bson:"base" json:"base"`
-	Category  string            `bson:"category" json:"category"` // noun|verb|adjective
-	Features  map[string]string `bson:"features" json:"features"`
-	Morphemes []Morpheme        `bson:"morphemes" json:"morphemes"`
-}
+Commands:
+  generate     Generate a question bank (default when no command is given)
+  regenerate   Rebuild a single question from its family and seed
+  validate     Check a bank file or a published version for structural problems
+  publish      Publish a generated bank file to MongoDB as a new version (after validating it)
+  rollback     Make an earlier bank version active again
+  versions     List published bank versions
+  report       List word bank words whose part of speech was inferred, with the evidence
 
-// Document inserted into MongoDB
-type QuestionDoc struct {
-	ID         int      `bson:"id" json:"id"`
-	Text       string   `bson:"text" json:"text"`
-	Choices    []string `bson:"choices" json:"choices"`
-	Answer     string   `bson:"answer" json:"answer"`
-	Difficulty string   `bson:"difficulty" json:"difficulty"`
-
-	QuestionText  string   `bson:"question_text" json:"question_text"`
-	QuestionType  string   `bson:"question_type" json:"question_type"` // TF|MC
-	CorrectAnswer string   `bson:"correct_answer" json:"correct_answer"`
-	Distractors   []string `bson:"distractors,omitempty" json:"distractors,omitempty"`
-	ViolatedRule  []string `bson:"violated_rule,omitempty" json:"violated_rule,omitempty"`
-	BaseWord      string   `bson:"base_word" json:"base_word"`
-	MorphemesUsed []string `bson:"morphemes_used" json:"morphemes_used"`
-}
+Run "generateQuestions <command> -h" for the flags of a command.
+`
 
-// Insert sample questions into the MongoDB collection
 func main() {
-	// Connect to MongoDB
-	client, err := ConnectDB()
-	if err != nil {
-		fmt.Println("Failed to connect to MongoDB:", err)
-		return
+	args := os.Args[1:]
+	cmd := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "generate":
+		err = runGenerate(args)
+	case "regenerate":
+		err = runRegenerate(args)
+	case "validate":
+		err = runValidate(args)
+	case "publish":
+		err = runPublish(args)
+	case "rollback":
+		err = runRollback(args)
+	case "versions":
+		err = runVersions(args)
+	case "report":
+		err = runReport(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
 	}
-
-	defer client.Disconnect(context.TODO())
-
-	// Access the database and questions collection
-	collection := client.Database("capymorphDB").Collection("questions")
-
-	questions := generateSampleQuestions()
-	if len(questions) == 0 {
-		fmt.Println("No questions generated")
-		return
-	}
-
-	res, err := collection.InsertMany(context.TODO(), questions, options.InsertMany().SetOrdered(false))
 	if err != nil {
-		fmt.Println("Failed to insert questions:", err)
-		return
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
 	}
-
-	fmt.Printf("Inserted %d questions\n", len(res.InsertedIDs))
 }
 
-// Generate a list of sample questions
-func generateSampleQuestions() []interface{} {
-	rand.Seed(time.Now().UnixNano())
+// generate: build a bank and write it to Mongo, stdout or a file
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	count := fs.Int("count", 512, "total number of questions to generate")
+	weights := fs.String("weights", "", "per-family weights, e.g. \"allomorphy=2,irregularity=0.5\" (unlisted families weigh 1)")
+	wordbank := fs.String("wordbank", defaultWordBankPath(), "path to the word bank")
+	affixes := fs.String("affixes", "", "affix rule file (JSON) added on top of the built-in rules")
+	pronunciations := fs.String("pronunciations", "", "CMU-style pronunciation dictionary added on top of the built-in one")
+	templates := fs.String("templates", "", "question template file (JSON) added on top of the built-in catalog")
+	language := fs.String("language", morphology.DefaultLanguage, "instruction language to word questions in")
+	out := fs.String("out", "mongo", "destination: \"mongo\" (publish), \"-\" for stdout, or a file path (JSON lines)")
+	version := fs.String("version", defaultVersionTag(), "bank version tag when publishing to mongo")
+	dryRun := fs.Bool("dry-run", false, "print sample questions per family instead of writing anything")
+	samples := fs.Int("samples", 2, "questions per family shown by --dry-run")
+	seed := fs.Int64("seed", 0, "random seed (0 picks one from the clock; the seed used is printed)")
+	fs.Parse(args)
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Fprintf(os.Stderr, "Using seed %d\n", *seed)
+
+	gen, err := loadGenerator(*wordbank, *affixes, *pronunciations, *templates, *language, *seed)
+	if err != nil {
+		return err
+	}
 
-	bankPath := filepath.Join(filepath.Dir(os.Args[0]), "wordbank.txt")
-	// When running via `go run`, os.Args[0] is a temp binary. Fall back to relative path.
-	if _, err := os.Stat(bankPath); err != nil {
-		bankPath = "wordbank.txt"
+	if *dryRun {
+		return preview(os.Stdout, gen, *samples)
 	}
 
-	words, err := loadWordBank(bankPath)
+	familyWeights, err := parseWeights(*weights)
 	if err != nil {
-		fmt.Println("Failed to read wordbank:", err)
-		return nil
+		return err
 	}
-
-	lex := newLexicon(words)
-	gen := newMorphGenerator(lex)
-
-	var docs []interface{}
-	nextID := 1
-
-	add := func(q QuestionDoc) {
-		q.ID = nextID
-		q.Text = q.QuestionText
-		q.Answer = q.CorrectAnswer
-		if q.QuestionType == "TF" {
-			q.Choices = []string{"True", "False"}
-		} else {
-			q.Choices = append([]string{q.CorrectAnswer}, q.Distractors...)
-			// Shuffle choices but keep correct_answer stable
-			r := rand.New(rand.NewSource(time.Now().UnixNano()))
-			r.Shuffle(len(q.Choices), func(i, j int) { q.Choices[i], q.Choices[j] = q.Choices[j], q.Choices[i] })
+	questions, err := morphology.Generate(gen, morphology.GenerateOptions{Count: *count, Weights: familyWeights})
+	if err != nil {
+		return err
+	}
+
+	switch *out {
+	case "mongo":
+		return publish(*version, *seed, questions)
+	case "-":
+		return writeJSONLines(os.Stdout, questions)
+	default:
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
 		}
-		docs = append(docs, q)
-		nextID++
-	}
-
-	// Generate a larger, balanced set.
-	// 8 families * 32 each = 256+ questions (guarantees each family appears many times).
-	const perFamily = 32
-	const minTotal = 512  // Tweak up minimal total to ensure variety
-
-	irregularAdded := 0
-	for i := 0; i < perFamily; i++ {
-		add(gen.qMorphemeClassification())
-		add(gen.qInflVsDeriv())
-		add(gen.qLexCategoryChange())
-		add(gen.qFeatureEncoding())
-		add(gen.qMorphemeCounting())
-		add(gen.qWellFormedness())
-		add(gen.qAllomorphy())
-		if q, ok := gen.qIrregularity(); ok {
-			add(q)
-			irregularAdded++
+		defer f.Close()
+		if err := writeJSONLines(f, questions); err != nil {
+			return err
 		}
+		fmt.Fprintf(os.Stderr, "Wrote %d questions to %s\n", len(questions), *out)
+		return nil
 	}
+}
 
-	// In the unlikely case the bank lacks irregular verbs, top up to ensure >250 total.
-	for len(docs) < minTotal {
-		add(gen.qInflVsDeriv())
-	}
-
-	_ = irregularAdded
+// regenerate: rebuild one question from the family and seed stored on its document
+func runRegenerate(args []string) error {
+	fs := flag.NewFlagSet("regenerate", flag.ExitOnError)
+	family := fs.String("family", "", "question family (the document's \"family\" field)")
+	seed := fs.Int64("seed", 0, "question seed (the document's \"seed\" field)")
+	wordbank := fs.String("wordbank", defaultWordBankPath(), "path to the word bank the question was generated from")
+	affixes := fs.String("affixes", "", "affix rule file the question was generated with, if any")
+	pronunciations := fs.String("pronunciations", "", "pronunciation dictionary the question was generated with, if any")
+	templates := fs.String("templates", "", "template file the question was generated with, if any")
+	language := fs.String("language", morphology.DefaultLanguage, "instruction language the question was worded in")
+	fs.Parse(args)
 
-	return docs
-}
+	if *family == "" {
+		return fmt.Errorf("-family is required (one of %s)", strings.Join(morphology.FamilyNames(), ", "))
+	}
 
-func loadWordBank(path string) ([]string, error) {
-	f, err := os.Open(path)
+	gen, err := loadGenerator(*wordbank, *affixes, *pronunciations, *templates, *language, *seed)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	var out []string
-	for scanner.Scan() {
-		w := strings.TrimSpace(scanner.Text())
-		if w == "" {
-			continue
-		}
-		out = append(out, w)
+	q, ok, err := morphology.Regenerate(gen, *family, *seed)
+	if err != nil {
+		return err
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if !ok {
+		return fmt.Errorf("family %q can't produce a question from this word bank", *family)
 	}
-	return out, nil
-}
-
-type Lexicon struct {
-	Words      []string
-	Verbs      []string
-	Nouns      []string
-	Adjectives []string
-	verbSet    map[string]bool
-	adjSet     map[string]bool
+	return writeJSONLines(os.Stdout, []morphology.QuestionDoc{q})
 }
 
-func newLexicon(words []string) *Lexicon {
-	verbSet := map[string]bool{}
-	adjSet := map[string]bool{}
-
-	verbs := []string{
-		"walk", "run", "jump", "swim", "climb", "build", "break", "carry", "drive", "eat", "drink", "sleep", "think",
-		"teach", "learn", "play", "work", "help", "move", "stop", "start", "open", "close", "watch", "listen", "talk",
-		"call", "follow", "lead", "push", "pull", "catch", "throw", "cut", "grow", "fall", "rise", "win", "lose",
-		"send", "receive", "graze", "forage", "float", "rest", "hide", "wander", "groom", "gather", "relax", "observe",
-		"communicate", "signal", "escape", "approach", "avoid", "enter", "leave", "share", "protect", "adapt",
+// Word bank next to the binary, falling back to paths relative to backend/ or backend/tools/
+func defaultWordBankPath() string {
+	candidates := []string{
+		filepath.Join(filepath.Dir(os.Args[0]), "wordbank.jsonl"),
+		"wordbank.jsonl",
+		filepath.Join("tools", "wordbank.jsonl"),
 	}
-	adjs := []string{
-		"happy", "sad", "fast", "slow", "big", "small", "young", "old", "easy", "hard", "strong", "weak", "quiet", "loud",
-		"bright", "dark", "clean", "dirty", "safe", "dangerous", "kind", "mean", "smart", "brave", "calm", "wild", "free",
-		"busy", "ready", "tired", "social", "gentle", "alert", "noisy", "steady", "curious", "peaceful", "wet", "dry", "warm",
-		"cool", "natural", "domestic", "aquatic", "terrestrial",
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
 	}
+	return "wordbank.jsonl"
+}
 
-	for _, v := range verbs {
-		verbSet[v] = true
-	}
-	for _, a := range adjs {
-		adjSet[a] = true
+func loadGenerator(wordbankPath, affixPath, pronunciationPath, templatePath, language string, seed int64) (*morphology.MorphGenerator, error) {
+	entries, err := morphology.LoadWordBank(wordbankPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wordbank: %w", err)
 	}
-
-	lex := &Lexicon{Words: words, verbSet: verbSet, adjSet: adjSet}
-	for _, w := range words {
-		if adjSet[w] {
-			lex.Adjectives = append(lex.Adjectives, w)
-		} else if verbSet[w] {
-			lex.Verbs = append(lex.Verbs, w)
-		} else {
-			lex.Nouns = append(lex.Nouns, w)
+	gen := morphology.NewMorphGenerator(morphology.NewLexicon(entries), rand.New(rand.NewSource(seed)))
+	if affixPath != "" {
+		set, err := morphology.LoadAffixRules(affixPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read affix rules: %w", err)
 		}
+		gen.UseAffixes(set)
 	}
-
-	// Fallback if wordbank didn't contain enough known categories
-	if len(lex.Verbs) == 0 {
-		lex.Verbs = verbs
-	}
-	if len(lex.Adjectives) == 0 {
-		lex.Adjectives = adjs
+	if pronunciationPath != "" {
+		p, err := morphology.LoadPronunciations(pronunciationPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pronunciations: %w", err)
+		}
+		gen.UsePronunciations(p)
 	}
-
-	return lex
-}
-
-func (l *Lexicon) categoryOf(word string) string {
-	if l.adjSet[word] {
-		return "adjective"
+	catalog := morphology.DefaultTemplates()
+	if templatePath != "" {
+		catalog, err = morphology.LoadTemplates(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates: %w", err)
+		}
 	}
-	if l.verbSet[word] {
-		return "verb"
+	if err := gen.UseTemplates(catalog, language); err != nil {
+		return nil, err
 	}
-	return "noun"
-}
-
-type MorphGenerator struct {
-	lex *Lexicon
-
-	irregularPast   map[string]string
-	irregularPlural map[string]string
+	return gen, nil
 }
 
-func newMorphGenerator(lex *Lexicon) *MorphGenerator {
-	irrPast := map[string]string{
-		"run":   "ran",
-		"eat":   "ate",
-		"drink": "drank",
-		"sleep": "slept",
-		"teach": "taught",
-		"think": "thought",
+// Parse "family=weight,family=weight"
+func parseWeights(spec string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	if strings.TrimSpace(spec) == "" {
+		return weights, nil
 	}
-	irrPlural := map[string]string{
-		"child": "children",
+	for _, pair := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("bad weight %q: expected family=weight", pair)
+		}
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad weight for %q: %w", name, err)
+		}
+		weights[name] = w
 	}
-	return &MorphGenerator{lex: lex, irregularPast: irrPast, irregularPlural: irrPlural}
+	return weights, nil
 }
 
-func (g *MorphGenerator) baseForm(word string) WordForm {
-	cat := g.lex.categoryOf(word)
-	return WordForm{
-		Surface:  word,
-		Base:     word,
-		Category: cat,
-		Features: map[string]string{},
-		Morphemes: []Morpheme{{
-			Surface:   word,
-			Role:      "root",
-			Bound:     false,
-			MorphType: "free",
-			Features:  map[string]string{},
-		}},
+// Print a few questions from every family
+func preview(w io.Writer, gen *morphology.MorphGenerator, samples int) error {
+	for _, name := range morphology.FamilyNames() {
+		questions, err := morphology.Sample(gen, name, samples)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "== %s ==\n", name)
+		if len(questions) == 0 {
+			fmt.Fprintln(w, "  (no questions: the word bank can't support this family)")
+		}
+		for _, q := range questions {
+			fmt.Fprintf(w, "  [%s/%s] %s\n", q.QuestionType, q.Difficulty, q.QuestionText)
+			fmt.Fprintf(w, "    choices: %s\n", strings.Join(q.Choices, " | "))
+			fmt.Fprintf(w, "    answer:  %s\n", q.CorrectAnswer)
+			fmt.Fprintf(w, "    explain: %s\n", q.Explanation)
+			fmt.Fprintf(w, "    template: %s\n", q.TemplateID)
+		}
+		fmt.Fprintln(w)
 	}
+	return nil
 }
 
-func (g *MorphGenerator) pluralize(noun WordForm) WordForm {
-	base := noun.Surface
-	if irr, ok := g.irregularPlural[base]; ok {
-		out := noun
-		out.Surface = irr
-		out.Features = map[string]string{"number": "plural"}
-		out.Morphemes = append([]Morpheme{}, noun.Morphemes...)
-		out.Morphemes = append(out.Morphemes, Morpheme{Surface: "PL", Role: "affix", Bound: true, MorphType: "inflectional", Features: map[string]string{"number": "plural", "allomorph": "irregular"}})
-		return out
-	}
-
-	sfx := "s"
-	if strings.HasSuffix(base, "s") || strings.HasSuffix(base, "x") || strings.HasSuffix(base, "z") || strings.HasSuffix(base, "ch") || strings.HasSuffix(base, "sh") {
-		sfx = "es"
-	} else if strings.HasSuffix(base, "y") && len(base) > 1 {
-		prev := base[len(base)-2]
-		if !strings.ContainsRune("aeiou", rune(prev)) {
-			base = base[:len(base)-1] + "i"
-			sfx = "es" // cities
+func writeJSONLines(w io.Writer, questions []morphology.QuestionDoc) error {
+	enc := json.NewEncoder(w)
+	for _, q := range questions {
+		if err := enc.Encode(q); err != nil {
+			return err
 		}
 	}
-
-	out := noun
-	out.Surface = base + sfx
-	out.Features = map[string]string{"number": "plural"}
-	out.Morphemes = append([]Morpheme{}, noun.Morphemes...)
-	out.Morphemes = append(out.Morphemes, Morpheme{Surface: sfx, Role: "affix", Bound: true, MorphType: "inflectional", Features: map[string]string{"number": "plural", "allomorph": sfx}})
-	return out
+	return nil
 }
 
-func (g *MorphGenerator) pastTense(verb WordForm) WordForm {
-	base := verb.Surface
-	if irr, ok := g.irregularPast[base]; ok {
-		out := verb
-		out.Surface = irr
-		out.Features = map[string]string{"tense": "past"}
-		out.Morphemes = append([]Morpheme{}, verb.Morphemes...)
-		out.Morphemes = append(out.Morphemes, Morpheme{Surface: "PST", Role: "affix", Bound: true, MorphType: "inflectional", Features: map[string]string{"tense": "past", "allomorph": "irregular"}})
-		return out
-	}
-
-	sfx := "ed"
-	stem := base
-	if strings.HasSuffix(base, "e") {
-		sfx = "d"
-	} else if strings.HasSuffix(base, "y") && len(base) > 1 {
-		prev := base[len(base)-2]
-		if !strings.ContainsRune("aeiou", rune(prev)) {
-			stem = base[:len(base)-1] + "i"
-		}
-	} else if len(base) >= 3 {
-		// crude CVC doubling: stop -> stopped
-		last := base[len(base)-1]
-		mid := base[len(base)-2]
-		prev := base[len(base)-3]
-		if !strings.ContainsRune("aeiou", rune(last)) && strings.ContainsRune("aeiou", rune(mid)) && !strings.ContainsRune("aeiou", rune(prev)) {
-			if !strings.ContainsRune("wxy", rune(last)) {
-				stem = base + string(last)
-			}
+// validate: check a bank file or a published version
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	in := fs.String("in", "", "bank file written by \"generate -out\" (JSON lines)")
+	live := fs.Bool("mongo", false, "validate a published version in MongoDB instead of a file")
+	version := fs.String("version", "", "with -mongo: version tag to check (default: the active version)")
+	fs.Parse(args)
+
+	var questions []morphology.QuestionDoc
+	var err error
+	switch {
+	case *live:
+		client, cerr := database.ConnectDB()
+		if cerr != nil {
+			return fmt.Errorf("failed to connect to MongoDB: %w", cerr)
 		}
+		defer client.Disconnect(context.TODO())
+		questions, err = questionbank.Load(client, *version)
+	case *in != "":
+		questions, err = readJSONLines(*in)
+	default:
+		return fmt.Errorf("pass -in <file> or -mongo")
+	}
+	if err != nil {
+		return err
 	}
 
-	out := verb
-	out.Surface = stem + sfx
-	out.Features = map[string]string{"tense": "past"}
-	out.Morphemes = append([]Morpheme{}, verb.Morphemes...)
-	out.Morphemes = append(out.Morphemes, Morpheme{Surface: sfx, Role: "affix", Bound: true, MorphType: "inflectional", Features: map[string]string{"tense": "past"}})
-	return out
-}
-
-func (g *MorphGenerator) deriveER(verb WordForm) WordForm {
-	stem := verb.Surface
-	sfx := "er"
-	if strings.HasSuffix(stem, "e") {
-		sfx = "r"
-	}
-	out := verb
-	out.Surface = stem + sfx
-	out.Category = "noun"
-	out.Features = map[string]string{"derived": "agent"}
-	out.Morphemes = append([]Morpheme{}, verb.Morphemes...)
-	out.Morphemes = append(out.Morphemes, Morpheme{Surface: sfx, Role: "affix", Bound: true, MorphType: "derivational", Features: map[string]string{"derivation": "verb->noun"}})
-	return out
-}
-
-func (g *MorphGenerator) deriveNESS(adj WordForm) WordForm {
-	stem := adj.Surface
-	if strings.HasSuffix(stem, "y") && len(stem) > 1 {
-		stem = stem[:len(stem)-1] + "i"
-	}
-	out := adj
-	out.Surface = stem + "ness"
-	out.Category = "noun"
-	out.Features = map[string]string{"derived": "state"}
-	out.Morphemes = append([]Morpheme{}, adj.Morphemes...)
-	out.Morphemes = append(out.Morphemes, Morpheme{Surface: "ness", Role: "affix", Bound: true, MorphType: "derivational", Features: map[string]string{"derivation": "adj->noun"}})
-	return out
+	issues := morphology.ValidateBank(questions)
+	if len(issues) > 0 {
+		return reportIssues(issues)
+	}
+	fmt.Printf("All %d questions passed validation\n", len(questions))
+	return nil
 }
 
-func (g *MorphGenerator) morphemeSurfaces(w WordForm) []string {
-	var m []string
-	for _, mor := range w.Morphemes {
-		m = append(m, mor.Surface)
+// Print every issue and return the validation error
+func reportIssues(issues []morphology.Issue) error {
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue)
 	}
-	return m
+	return &morphology.ValidationError{Issues: issues}
 }
 
-func (g *MorphGenerator) pickVerb() string { return g.lex.Verbs[rand.Intn(len(g.lex.Verbs))] }
-func (g *MorphGenerator) pickNoun() string { return g.lex.Nouns[rand.Intn(len(g.lex.Nouns))] }
-func (g *MorphGenerator) pickAdj() string  { return g.lex.Adjectives[rand.Intn(len(g.lex.Adjectives))] }
-
-// --- Question families ---
+// publish: push a bank file (from "generate -out") to MongoDB as a version
+func runPublish(args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	in := fs.String("in", "", "bank file written by \"generate -out\" (JSON lines)")
+	version := fs.String("version", defaultVersionTag(), "bank version tag")
+	fs.Parse(args)
 
-func (g *MorphGenerator) qMorphemeClassification() QuestionDoc {
-	// Ensure >=2 morphemes via derivation then optional inflection
-	verb := g.baseForm(g.pickVerb())
-	word := g.deriveER(verb)
-	if rand.Intn(2) == 0 {
-		word = g.pluralize(word)
+	if *in == "" {
+		return fmt.Errorf("-in is required")
 	}
-
-	// pick an affix morpheme
-	var target Morpheme
-	for i := len(word.Morphemes) - 1; i >= 0; i-- {
-		if word.Morphemes[i].Role == "affix" {
-			target = word.Morphemes[i]
-			break
-		}
+	questions, err := readJSONLines(*in)
+	if err != nil {
+		return err
 	}
+	return publish(*version, 0, questions)
+}
 
-	props := []string{"bound", "free", "root", "affix", "derivational", "inflectional"}
-	prop := props[rand.Intn(len(props))]
-
-	trueVal := false
-	switch prop {
-	case "bound":
-		trueVal = target.Bound
-	case "free":
-		trueVal = !target.Bound
-	case "root":
-		trueVal = target.Role == "root"
-	case "affix":
-		trueVal = target.Role == "affix"
-	case "derivational":
-		trueVal = target.MorphType == "derivational"
-	case "inflectional":
-		trueVal = target.MorphType == "inflectional"
-	}
-
-	makeFalse := rand.Intn(2) == 0
-	correct := "True"
-	violated := ""
-	if makeFalse {
-		// flip exactly one property
-		trueVal = !trueVal
-		correct = "False"
-		violated = "flipped_morpheme_property"
-	}
-
-	stmt := fmt.Sprintf("True/False: In the word %q, the morpheme %q is %s.", word.Surface, target.Surface, prop)
-
-	return QuestionDoc{
-		Difficulty:    "easy",
-		QuestionText:  stmt,
-		QuestionType:  "TF",
-		CorrectAnswer: correct,
-		ViolatedRule:  []string{violated},
-		BaseWord:      word.Base,
-		MorphemesUsed: g.morphemeSurfaces(word),
+// rollback: re-activate an earlier version
+func runRollback(args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	version := fs.String("version", "", "bank version tag to make active")
+	fs.Parse(args)
+
+	if *version == "" {
+		return fmt.Errorf("-version is required (see the versions command)")
 	}
-}
 
-func (g *MorphGenerator) qInflVsDeriv() QuestionDoc {
-	// One option: only inflectional
-	noun := g.baseForm(g.pickNoun())
-	correctW := g.pluralize(noun)
-
-	// Distractors: must include derivational affix
-	v1 := g.deriveER(g.baseForm(g.pickVerb()))
-	v2 := g.deriveNESS(g.baseForm(g.pickAdj()))
-	v3 := g.pluralize(g.deriveER(g.baseForm(g.pickVerb())))
-
-	correctAnswer := correctW.Surface
-	distractors := []string{v1.Surface, v2.Surface, v3.Surface}
-	violated := []string{"contains_derivational_affix", "contains_derivational_affix", "contains_derivational_affix"}
-
-	q := "Which word contains only inflectional morphology?"
-	return QuestionDoc{
-		Difficulty:    "medium",
-		QuestionText:  q,
-		QuestionType:  "MC",
-		CorrectAnswer: correctAnswer,
-		Distractors:   distractors,
-		ViolatedRule:  violated,
-		BaseWord:      correctW.Base,
-		MorphemesUsed: g.morphemeSurfaces(correctW),
+	client, err := database.ConnectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
-}
+	defer client.Disconnect(context.TODO())
 
-func (g *MorphGenerator) qLexCategoryChange() QuestionDoc {
-	adj := g.baseForm(g.pickAdj())
-	derived := g.deriveNESS(adj)
-
-	makeFalse := rand.Intn(2) == 0
-	correct := "True"
-	violated := ""
-	if makeFalse {
-		correct = "False"
-		violated = "incorrect_category_change_claim"
-	}
-
-	stmt := fmt.Sprintf("True/False: Adding %q to %q changes its lexical category.", "-ness", adj.Surface)
-	// True statement for -ness is category-changing; false flips that single claim.
-	return QuestionDoc{
-		Difficulty:    "easy",
-		QuestionText:  stmt,
-		QuestionType:  "TF",
-		CorrectAnswer: correct,
-		ViolatedRule:  []string{violated},
-		BaseWord:      derived.Base,
-		MorphemesUsed: g.morphemeSurfaces(derived),
+	if err := questionbank.Activate(client, *version); err != nil {
+		return err
 	}
+	fmt.Printf("Version %s is now active\n", *version)
+	return nil
 }
 
-func (g *MorphGenerator) qFeatureEncoding() QuestionDoc {
-	verb := g.baseForm(g.pickVerb())
-	inflected := g.pastTense(verb)
+// versions: list published versions
+func runVersions(args []string) error {
+	fs := flag.NewFlagSet("versions", flag.ExitOnError)
+	fs.Parse(args)
 
-	suffix := inflected.Morphemes[len(inflected.Morphemes)-1].Surface
-	q := fmt.Sprintf("What grammatical feature does the suffix %q encode in %q?", suffix, inflected.Surface)
-
-	correct := "tense: past"
-	distractors := []string{"number: plural", "aspect: progressive", "person: 3"}
-	violated := []string{"feature_mismatch", "feature_mismatch", "feature_mismatch"}
-
-	return QuestionDoc{
-		Difficulty:    "easy",
-		QuestionText:  q,
-		QuestionType:  "MC",
-		CorrectAnswer: correct,
-		Distractors:   distractors,
-		ViolatedRule:  violated,
-		BaseWord:      inflected.Base,
-		MorphemesUsed: g.morphemeSurfaces(inflected),
+	client, err := database.ConnectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
-}
+	defer client.Disconnect(context.TODO())
 
-func (g *MorphGenerator) qMorphemeCounting() QuestionDoc {
-	verb := g.baseForm(g.pickVerb())
-	derived := g.deriveER(verb)
-	word := g.pluralize(derived)
-
-	count := len(word.Morphemes)
-	q := fmt.Sprintf("How many morphemes are in %q?", word.Surface)
-	correct := fmt.Sprintf("%d", count)
-	distractors := []string{fmt.Sprintf("%d", count-1), fmt.Sprintf("%d", count+1), fmt.Sprintf("%d", count+2)}
-	violated := []string{"wrong_morpheme_count", "wrong_morpheme_count", "wrong_morpheme_count"}
-
-	return QuestionDoc{
-		Difficulty:    "medium",
-		QuestionText:  q,
-		QuestionType:  "MC",
-		CorrectAnswer: correct,
-		Distractors:   distractors,
-		ViolatedRule:  violated,
-		BaseWord:      word.Base,
-		MorphemesUsed: g.morphemeSurfaces(word),
+	list, err := questionbank.ListVersions(client)
+	if err != nil {
+		return err
 	}
-}
-
-func (g *MorphGenerator) qWellFormedness() QuestionDoc {
-	// One ill-formed word: -ness attached to a verb (violates selectional restriction)
-	verb := g.baseForm(g.pickVerb())
-	ill := WordForm{Surface: verb.Surface + "ness", Base: verb.Base, Category: "noun", Features: map[string]string{}, Morphemes: []Morpheme{
-		{Surface: verb.Surface, Role: "root", Bound: false, MorphType: "free", Features: map[string]string{}},
-		{Surface: "ness", Role: "affix", Bound: true, MorphType: "derivational", Features: map[string]string{"derivation": "adj->noun"}},
-	}}
-
-	// Well-formed distractors
-	a1 := g.deriveNESS(g.baseForm(g.pickAdj()))
-	a2 := g.deriveER(g.baseForm(g.pickVerb()))
-	a3 := g.pluralize(g.baseForm(g.pickNoun()))
-
-	q := "Which word is NOT well-formed (under the affix rules used in this quiz)?"
-	correct := ill.Surface
-	distractors := []string{a1.Surface, a2.Surface, a3.Surface}
-	violated := []string{"", "", ""}
-
-	return QuestionDoc{
-		Difficulty:    "hard",
-		QuestionText:  q,
-		QuestionType:  "MC",
-		CorrectAnswer: correct,
-		Distractors:   distractors,
-		ViolatedRule:  append([]string{"violates_-ness_selection"}, violated...),
-		BaseWord:      ill.Base,
-		MorphemesUsed: g.morphemeSurfaces(ill),
+	for _, v := range list {
+		marker := " "
+		if v.Active {
+			marker = "*"
+		}
+		fmt.Printf("%s %-24s %5d questions  published %s\n", marker, v.Tag, v.Count, v.PublishedAt.Format(time.RFC3339))
 	}
+	return nil
 }
 
-func (g *MorphGenerator) qAllomorphy() QuestionDoc {
-	// Ask specifically for the plural allomorph spelled "es"
-	// Choose one noun that triggers -es
-	var esBase string
-	for {
-		cand := g.pickNoun()
-		if strings.HasSuffix(cand, "s") || strings.HasSuffix(cand, "x") || strings.HasSuffix(cand, "z") || strings.HasSuffix(cand, "ch") || strings.HasSuffix(cand, "sh") {
-			esBase = cand
-			break
-		}
-	}
-	correctW := g.pluralize(g.baseForm(esBase))
+// report: list inferred categories so curators know which words to annotate
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	wordbank := fs.String("wordbank", defaultWordBankPath(), "path to the word bank")
+	format := fs.String("format", "text", "output format: \"text\" or \"json\" (JSON lines)")
+	fs.Parse(args)
 
-	// Distractors: plural with -s (not -es)
-	var d1, d2, d3 string
-	for {
-		cand := g.pickNoun()
-		if cand == esBase {
-			continue
-		}
-		p := g.pluralize(g.baseForm(cand))
-		if strings.HasSuffix(p.Surface, "s") && !strings.HasSuffix(p.Surface, "es") {
-			d1 = p.Surface
-			break
-		}
-	}
-	for {
-		cand := g.pickNoun()
-		p := g.pluralize(g.baseForm(cand))
-		if p.Surface != d1 && strings.HasSuffix(p.Surface, "s") && !strings.HasSuffix(p.Surface, "es") {
-			d2 = p.Surface
-			break
+	entries, err := morphology.LoadWordBank(*wordbank)
+	if err != nil {
+		return fmt.Errorf("failed to read wordbank: %w", err)
+	}
+	inferences := morphology.NewLexicon(entries).Inferences()
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		for _, inf := range inferences {
+			row := struct {
+				morphology.Inference
+				Used bool `json:"used"`
+			}{inf, inf.Used()}
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
 		}
-	}
-	for {
-		cand := g.pickNoun()
-		p := g.pluralize(g.baseForm(cand))
-		if p.Surface != d1 && p.Surface != d2 && strings.HasSuffix(p.Surface, "s") && !strings.HasSuffix(p.Surface, "es") {
-			d3 = p.Surface
-			break
+	case "text":
+		excluded := 0
+		for _, inf := range inferences {
+			status := "used"
+			switch {
+			case !inf.Used():
+				status = "excluded"
+				excluded++
+			case inf.Derived:
+				status = "derived" // Categorized, but never a root to build on
+			}
+			pos := inf.POS
+			if inf.Number != "" {
+				pos += " (" + inf.Number + ")"
+			}
+			fmt.Printf("%-20s %-18s %.2f  %-8s  %s\n", inf.Word, pos, inf.Confidence, status, strings.Join(inf.Evidence, "; "))
 		}
+		fmt.Printf("\n%d inferred word(s), %d excluded below confidence %.2f\n", len(inferences), excluded, morphology.MinInferenceConfidence)
+	default:
+		return fmt.Errorf("unknown format %q (want text or json)", *format)
 	}
+	return nil
+}
 
-	q := "Which word contains the plural allomorph spelled \"es\"?"
-	return QuestionDoc{
-		Difficulty:    "medium",
-		QuestionText:  q,
-		QuestionType:  "MC",
-		CorrectAnswer: correctW.Surface,
-		Distractors:   []string{d1, d2, d3},
-		ViolatedRule:  []string{"uses_default_plural_-s", "uses_default_plural_-s", "uses_default_plural_-s"},
-		BaseWord:      correctW.Base,
-		MorphemesUsed: g.morphemeSurfaces(correctW),
-	}
+// Default version tag: the current UTC time
+func defaultVersionTag() string {
+	return time.Now().UTC().Format("bank-20060102-150405")
 }
 
-func (g *MorphGenerator) qIrregularity() (QuestionDoc, bool) {
-	// Pick an irregular verb that exists in the bank
-	var irrBases []string
-	for b := range g.irregularPast {
-		for _, w := range g.lex.Verbs {
-			if w == b {
-				irrBases = append(irrBases, b)
-				break
-			}
-		}
-	}
-	if len(irrBases) == 0 {
-		return QuestionDoc{}, false
+func readJSONLines(path string) ([]morphology.QuestionDoc, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
-	base := irrBases[rand.Intn(len(irrBases))]
-	correct := base
+	defer f.Close()
 
-	// Distractors: regular verbs
-	distractors := []string{}
-	violated := []string{}
-	for len(distractors) < 3 {
-		cand := g.pickVerb()
-		if cand == correct {
-			continue
-		}
-		if _, ok := g.irregularPast[cand]; ok {
-			continue
-		}
-		already := false
-		for _, d := range distractors {
-			if d == cand {
-				already = true
-				break
-			}
+	var out []morphology.QuestionDoc
+	dec := json.NewDecoder(f)
+	for {
+		var q morphology.QuestionDoc
+		err := dec.Decode(&q)
+		if err == io.EOF {
+			return out, nil
 		}
-		if already {
-			continue
+		if err != nil {
+			return nil, fmt.Errorf("%s: question %d: %w", path, len(out)+1, err)
 		}
-		distractors = append(distractors, cand)
-		violated = append(violated, "regular_past_(-ed)")
-	}
-
-	q := "Which verb has an irregular past tense?"
-	return QuestionDoc{
-		Difficulty:    "hard",
-		QuestionText:  q,
-		QuestionType:  "MC",
-		CorrectAnswer: correct,
-		Distractors:   distractors,
-		ViolatedRule:  violated,
-		BaseWord:      correct,
-		MorphemesUsed: []string{correct},
-	}, true
-}
-
-// ConnectDB establishes a connection to MongoDB and returns the client.
-// Kept in this file so `go run tools/generateQuestions.go` works when invoked directly.
-func ConnectDB() (*mongo.Client, error) {
-	// Support running from either `backend/` or `backend/tools/`
-	if err := godotenv.Load(); err != nil {
-		_ = godotenv.Load(filepath.Join("tools", ".env"))
+		out = append(out, q)
 	}
+}
 
-	uri := os.Getenv("MONGODB_URI")
-	if uri == "" {
-		return nil, fmt.Errorf("missing MONGODB_URI environment variable")
+// Publish the questions to MongoDB as the active version
+func publish(version string, seed int64, questions []morphology.QuestionDoc) error {
+	// Refuse to publish a bank that fails validation
+	if issues := morphology.ValidateBank(questions); len(issues) > 0 {
+		return reportIssues(issues)
 	}
-	if strings.Contains(uri, "<db_password>") {
-		return nil, fmt.Errorf("MONGODB_URI still contains <db_password>; replace it with the real password (URL-encode special characters)")
-	}
-
-	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().ApplyURI(uri).SetServerAPIOptions(serverAPI)
 
-	client, err := mongo.Connect(opts)
+	client, err := database.ConnectDB()
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
+	defer client.Disconnect(context.TODO())
 
-	if err := client.Ping(context.TODO(), readpref.Primary()); err != nil {
-		return nil, err
+	if err := questionbank.Publish(client, version, seed, questions); err != nil {
+		return fmt.Errorf("failed to publish questions: %w", err)
 	}
 
-	return client, nil
+	fmt.Printf("Published %d questions as version %s (now active)\n", len(questions), version)
+	return nil
 }